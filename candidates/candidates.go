@@ -0,0 +1,239 @@
+package candidates
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sudoku/utils"
+	"sudoku/validator"
+)
+
+// ErrInvalidCount is returned by Parse unless there are exactly 81 cells
+var ErrInvalidCount = errors.New("Error: Invalid number of cells")
+
+// ErrInvalidCandidate is returned by Parse for anything but digits 1-9 or '.'
+var ErrInvalidCandidate = errors.New("Error: Invalid candidate")
+
+// Set holds the pencil marks of one cell
+// Bit n is set while digit n is possible, matching validator.Tracker masks
+type Set uint16
+
+// All is the set of every digit 1-9
+const All = Set(validator.AllDigits)
+
+// NewSet creates a set holding the given digits
+func NewSet(digits ...int) Set {
+	var s Set
+	for _, digit := range digits {
+		s = s.With(digit)
+	}
+	return s
+}
+
+// Has checks if digit is in the set
+func (s Set) Has(digit int) bool {
+	return s&(1<<digit) != 0
+}
+
+// With returns the set with digit added
+func (s Set) With(digit int) Set {
+	return s | 1<<digit
+}
+
+// Without returns the set with digit removed
+func (s Set) Without(digit int) Set {
+	return s &^ (1 << digit)
+}
+
+// Count returns the number of digits in the set
+func (s Set) Count() int {
+	return validator.CountCandidates(uint16(s))
+}
+
+// Digits lists the digits in the set in increasing order
+func (s Set) Digits() []int {
+	var result []int
+	for digit := 1; digit <= 9; digit++ {
+		if s.Has(digit) {
+			result = append(result, digit)
+		}
+	}
+	return result
+}
+
+// String formats the set as its digits, e.g. "139", or "." when empty
+func (s Set) String() string {
+	if s == 0 {
+		return "."
+	}
+	var b strings.Builder
+	for _, digit := range s.Digits() {
+		b.WriteByte(utils.IntToChar(digit))
+	}
+	return b.String()
+}
+
+// Grid holds the pencil marks of every cell, indexed [row][col]
+// Filled cells have an empty set
+type Grid [9][9]Set
+
+// Compute works out the candidates of every empty cell from the digits
+// already in its row, column and box
+func Compute(board *utils.Board) Grid {
+	var g Grid
+	work := *board
+	tracker := validator.NewTracker(&work)
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if board[row][col] == 0 {
+				g[row][col] = Set(tracker.Candidates(row, col))
+			}
+		}
+	}
+	return g
+}
+
+// Has checks if digit is a candidate of (row, col)
+func (g *Grid) Has(row, col, digit int) bool {
+	return g[row][col].Has(digit)
+}
+
+// Add pencils digit into (row, col)
+func (g *Grid) Add(row, col, digit int) {
+	g[row][col] = g[row][col].With(digit)
+}
+
+// Remove rubs digit out of (row, col)
+// Returns true if it was a candidate
+func (g *Grid) Remove(row, col, digit int) bool {
+	had := g.Has(row, col, digit)
+	g[row][col] = g[row][col].Without(digit)
+	return had
+}
+
+// Place records digit as the value of (row, col): the cell loses its own
+// candidates and digit is removed from every peer
+func (g *Grid) Place(row, col, digit int) {
+	g[row][col] = 0
+	for _, peer := range Peers(row, col) {
+		g.Remove(peer.Row, peer.Col, digit)
+	}
+}
+
+// String serialises the grid as nine lines of nine space-separated cells,
+// each written as its candidate digits or '.' when empty (see Parse)
+func (g Grid) String() string {
+	lines := make([]string, 9)
+	for row := 0; row < 9; row++ {
+		cells := make([]string, 9)
+		for col := 0; col < 9; col++ {
+			cells[col] = g[row][col].String()
+		}
+		lines[row] = strings.Join(cells, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Parse reads a grid written by Grid.String
+// Any whitespace separates cells; there must be exactly 81
+func Parse(text string) (Grid, error) {
+	var g Grid
+	fields := strings.Fields(text)
+	if len(fields) != 81 {
+		return g, ErrInvalidCount
+	}
+
+	for i, field := range fields {
+		if field == "." {
+			continue
+		}
+		for j := 0; j < len(field); j++ {
+			if field[j] < '1' || field[j] > '9' {
+				return g, fmt.Errorf("%w %q at (%d, %d)", ErrInvalidCandidate, field, i/9, i%9)
+			}
+			g.Add(i/9, i%9, utils.CharToInt(field[j]))
+		}
+	}
+	return g, nil
+}
+
+// Format draws the pencil marks as a 27x27 grid of characters, each cell
+// a 3x3 block with digit n at position n in reading order ('.' if ruled out)
+// Filled cells of board show their digit in the middle of a blank block
+// Returns the 27 lines
+func Format(board *utils.Board, g *Grid) []string {
+	lines := make([]string, 27)
+	for line := 0; line < 27; line++ {
+		row, sub := line/3, line%3
+		text := make([]byte, 27)
+		for col := 0; col < 9; col++ {
+			for i := 0; i < 3; i++ {
+				digit := sub*3 + i + 1
+				text[col*3+i] = markChar(board[row][col], g[row][col], digit)
+			}
+		}
+		lines[line] = string(text)
+	}
+	return lines
+}
+
+// Print prints the 27x27 pencil-mark grid from Format, one line each
+func Print(board *utils.Board, g *Grid) {
+	Fprint(os.Stdout, board, g)
+}
+
+// Fprint is Print writing to w
+func Fprint(w io.Writer, board *utils.Board, g *Grid) {
+	for _, line := range Format(board, g) {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// markChar returns the character drawn for digit's position in a cell
+func markChar(value int, set Set, digit int) byte {
+	switch {
+	case value != 0 && digit == 5:
+		return utils.IntToChar(value) // Centre of a filled cell
+	case value != 0:
+		return ' '
+	case set.Has(digit):
+		return utils.IntToChar(digit)
+	default:
+		return '.'
+	}
+}
+
+// peers lists, for every cell, the 20 other cells sharing a unit with it
+var peers = buildPeers()
+
+// Peers returns the 20 cells sharing a row, column or box with (row, col)
+func Peers(row, col int) []validator.Cell {
+	return peers[row][col]
+}
+
+// Sees checks if two cells share a row, column or box
+func Sees(a, b validator.Cell) bool {
+	return a.Row == b.Row || a.Col == b.Col ||
+		validator.BoxIndex(a.Row, a.Col) == validator.BoxIndex(b.Row, b.Col)
+}
+
+// buildPeers computes the peer list of every cell
+func buildPeers() [9][9][]validator.Cell {
+	var result [9][9][]validator.Cell
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			cell := validator.Cell{Row: row, Col: col}
+			for r := 0; r < 9; r++ {
+				for c := 0; c < 9; c++ {
+					other := validator.Cell{Row: r, Col: c}
+					if other != cell && Sees(cell, other) {
+						result[row][col] = append(result[row][col], other)
+					}
+				}
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"sudoku/solver"
+	"sudoku/utils"
+	"sudoku/validator"
+)
+
+// ErrNoUniqueSolution is returned when the original puzzle has no single
+// solution to check against
+var ErrNoUniqueSolution = errors.New("Error: Puzzle does not have a unique solution")
+
+// Mistake is a player entry that differs from the solution
+type Mistake struct {
+	Row, Col int
+	Entered  int // Digit the player entered
+	Expected int // Digit the solution has
+}
+
+// String formats the mistake as "(row, col) is X, should be Y"
+func (m Mistake) String() string {
+	return fmt.Sprintf("(%d, %d) is %d, should be %d", m.Row, m.Col, m.Entered, m.Expected)
+}
+
+// Report lists the problems with a player's board
+// Conflicts are direct clashes a player can see on the board; Mistakes are
+// entries that contradict the solution even if no rule is broken yet
+// A wrong entry that also clashes appears in both lists
+type Report struct {
+	Conflicts     []validator.Conflict // Duplicate digits in a row, column or box
+	Mistakes      []Mistake            // Entries that differ from the solution
+	ChangedGivens []validator.Cell     // Given cells the player cleared or overwrote
+}
+
+// OK checks if the player has made no errors so far
+func (r Report) OK() bool {
+	return len(r.Conflicts) == 0 && len(r.Mistakes) == 0 && len(r.ChangedGivens) == 0
+}
+
+// Check compares a player's board with the solution of the original puzzle
+// Empty cells are never errors, so a board in progress can be checked
+// Returns ErrNoUniqueSolution if the puzzle cannot be solved unambiguously
+func Check(puzzle, player *utils.Board) (Report, error) {
+	solutions := solver.FindSolutions(puzzle, 2)
+	if len(solutions) != 1 {
+		return Report{}, ErrNoUniqueSolution
+	}
+	return Compare(puzzle, &solutions[0], player), nil
+}
+
+// Compare is Check for callers that already know the solution
+func Compare(puzzle, solution, player *utils.Board) Report {
+	report := Report{Conflicts: validator.ValidateBoard(player)}
+
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			given, entered := puzzle[row][col], player[row][col]
+			switch {
+			case given != 0 && entered != given:
+				report.ChangedGivens = append(report.ChangedGivens, validator.Cell{Row: row, Col: col})
+			case given == 0 && entered != 0 && entered != solution[row][col]:
+				report.Mistakes = append(report.Mistakes, Mistake{
+					Row: row, Col: col, Entered: entered, Expected: solution[row][col],
+				})
+			}
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"math/rand"
+	"sudoku/solver"
+	"sudoku/utils"
+	"sudoku/validator"
+)
+
+// Generate creates a random puzzle with exactly one solution
+// The same rng state always produces the same puzzle
+func Generate(rng *rand.Rand) utils.Board {
+	grid := FullGrid(rng)
+	return RemoveClues(rng, &grid)
+}
+
+// FullGrid creates a random completely filled, valid board
+func FullGrid(rng *rand.Rand) utils.Board {
+	board, _ := fullGrid(rng, nil) // Classic rules always leave a solution
+	return board
+}
+
+// fullGrid is FullGrid obeying the extra rules of a variant
+// Returns false if the rules together leave no solution at all
+func fullGrid(rng *rand.Rand, variant *validator.Variant) (utils.Board, bool) {
+	board := utils.NewBoard()
+	backend := &solver.Backtracker{Rand: rng, Variant: variant}
+	return board, backend.Solve(&board)
+}
+
+// RemoveClues empties cells of a solved board in random order, keeping each
+// removal only if the puzzle still has a unique solution
+// Returns a minimal puzzle: removing any further clue makes it ambiguous
+func RemoveClues(rng *rand.Rand, grid *utils.Board) utils.Board {
+	return removeClues(rng, grid, NoSymmetry, 0, nil)
+}
+
+// removeClues is RemoveClues that removes whole symmetry orbits at a time,
+// never goes below minGivens clues and keeps the solution unique under the
+// rules of a variant
+func removeClues(rng *rand.Rand, grid *utils.Board, symmetry Symmetry, minGivens int, variant *validator.Variant) utils.Board {
+	puzzle := *grid
+	givens := 81
+	for _, cell := range rng.Perm(81) {
+		row, col := cell/9, cell%9
+		if puzzle[row][col] == 0 {
+			continue // Already removed as part of an earlier orbit
+		}
+		orbit := symmetry.Orbit(row, col)
+		if givens-len(orbit) < minGivens {
+			continue
+		}
+
+		// Try without these clues and put them back if uniqueness is lost
+		for _, c := range orbit {
+			puzzle[c.Row][c.Col] = 0
+		}
+		if hasUniqueSolution(&puzzle, variant) {
+			givens -= len(orbit)
+			continue
+		}
+		for _, c := range orbit {
+			puzzle[c.Row][c.Col] = grid[c.Row][c.Col]
+		}
+	}
+	return puzzle
+}
+
+// CountGivens counts the filled cells of a board
+func CountGivens(board *utils.Board) int {
+	count := 0
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if board[row][col] != 0 {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// HasUniqueSolution checks if the board has exactly one solution
+// Stops searching as soon as a second solution is found
+func HasUniqueSolution(board *utils.Board) bool {
+	return hasUniqueSolution(board, nil)
+}
+
+// hasUniqueSolution is HasUniqueSolution under the rules of a variant
+func hasUniqueSolution(board *utils.Board, variant *validator.Variant) bool {
+	counter := &solver.Backtracker{Propagate: true, Variant: variant}
+	return counter.CountSolutions(board, 2) == 1
+}
@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sudoku/grader"
+	"sudoku/utils"
+	"sudoku/validator"
+)
+
+// ErrTargetNotMet is returned when no attempt produced a puzzle meeting
+// the options
+var ErrTargetNotMet = errors.New("Error: Could not generate a puzzle meeting the target")
+
+// ErrInvalidOptions is returned for options no puzzle could ever meet
+var ErrInvalidOptions = errors.New("Error: Invalid generator options")
+
+// Options constrains the puzzles GenerateWith accepts
+// Start from DefaultOptions, which accepts every puzzle
+type Options struct {
+	MinLevel    grader.Level // Easiest acceptable rating
+	MaxLevel    grader.Level // Hardest acceptable rating
+	MinGivens   int          // Clue removal stops before going below this
+	MaxGivens   int          // Puzzles with more clues are rejected
+	Symmetry    Symmetry     // Pattern the clue positions must follow
+	MaxAttempts int          // Full grids to try before giving up
+
+	// Extra rules the puzzle follows, such as validator.Windows() (nil for
+	// classic); jigsaw variants are not supported, as the grader cannot rate
+	// them
+	Variant *validator.Variant
+}
+
+// DefaultOptions returns options that accept any minimal puzzle
+func DefaultOptions() Options {
+	return Options{
+		MinLevel:    grader.Easy,
+		MaxLevel:    grader.Diabolical,
+		MinGivens:   17,
+		MaxGivens:   81,
+		Symmetry:    NoSymmetry,
+		MaxAttempts: 100,
+	}
+}
+
+// Puzzle is a generated puzzle together with its rating
+type Puzzle struct {
+	Board    utils.Board
+	Rating   grader.Rating
+	Givens   int // Number of clues
+	Attempts int // Full grids tried, including the successful one
+}
+
+// GenerateWith creates puzzles until one meets the options
+// Each attempt starts from a new full grid and removes clues (following the
+// symmetry) until no more can go, or MinGivens is reached
+// Returns ErrTargetNotMet, with the reasons attempts failed, once
+// MaxAttempts is used up, and ErrInvalidOptions if the variant's rules
+// leave no solution
+func GenerateWith(rng *rand.Rand, opts Options) (Puzzle, error) {
+	if err := opts.validate(); err != nil {
+		return Puzzle{}, err
+	}
+
+	tooMany, tooEasy, tooHard := 0, 0, 0
+	for attempt := 1; attempt <= opts.MaxAttempts; attempt++ {
+		grid, ok := fullGrid(rng, opts.Variant)
+		if !ok {
+			return Puzzle{}, fmt.Errorf("%w: %v variant has no solution", ErrInvalidOptions, opts.Variant)
+		}
+		board := removeClues(rng, &grid, opts.Symmetry, opts.MinGivens, opts.Variant)
+
+		// Reject puzzles that still have too many clues
+		givens := CountGivens(&board)
+		if givens > opts.MaxGivens {
+			tooMany++
+			continue
+		}
+
+		// Reject puzzles outside the target difficulty
+		rating, err := grader.GradeVariant(&board, opts.Variant)
+		if err != nil {
+			return Puzzle{}, err
+		}
+		if rating.Level < opts.MinLevel {
+			tooEasy++
+			continue
+		}
+		if rating.Level > opts.MaxLevel {
+			tooHard++
+			continue
+		}
+
+		return Puzzle{Board: board, Rating: rating, Givens: givens, Attempts: attempt}, nil
+	}
+
+	return Puzzle{}, fmt.Errorf("%w in %d attempts (%d had too many givens, %d too easy, %d too hard)",
+		ErrTargetNotMet, opts.MaxAttempts, tooMany, tooEasy, tooHard)
+}
+
+// validate rejects options that contradict themselves
+func (o Options) validate() error {
+	switch {
+	case o.MinLevel > o.MaxLevel:
+		return fmt.Errorf("%w: minimum level %v is above maximum %v", ErrInvalidOptions, o.MinLevel, o.MaxLevel)
+	case o.MinGivens > o.MaxGivens:
+		return fmt.Errorf("%w: minimum givens %d is above maximum %d", ErrInvalidOptions, o.MinGivens, o.MaxGivens)
+	case o.Variant == nil && o.MaxGivens < 17:
+		return fmt.Errorf("%w: no unique classic puzzle has fewer than 17 givens", ErrInvalidOptions)
+	case o.MaxAttempts < 1:
+		return fmt.Errorf("%w: at least one attempt is needed", ErrInvalidOptions)
+	case o.Variant.HasIrregularBoxes():
+		return fmt.Errorf("%w: %v puzzles cannot be generated", ErrInvalidOptions, o.Variant)
+	}
+	return nil
+}
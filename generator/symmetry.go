@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"fmt"
+	"sudoku/utils"
+	"sudoku/validator"
+)
+
+// Symmetry is a pattern the positions of the givens must follow
+// Clues are removed a whole orbit at a time so the pattern is kept
+type Symmetry int
+
+const (
+	NoSymmetry    Symmetry = iota // Any clue layout
+	Rotational180                 // Unchanged by a half turn about the centre
+	Rotational90                  // Unchanged by a quarter turn (implies 180)
+	Horizontal                    // Mirrored top to bottom across the middle row
+	Vertical                      // Mirrored left to right across the middle column
+	Diagonal                      // Mirrored across the top-left to bottom-right diagonal
+	AntiDiagonal                  // Mirrored across the top-right to bottom-left diagonal
+	Dihedral                      // Unchanged by every rotation and mirror above
+	symmetryCount
+)
+
+// symmetryNames holds the command-line name of every symmetry
+var symmetryNames = [...]string{"none", "180", "90", "horizontal", "vertical", "diagonal", "anti-diagonal", "dihedral"}
+
+// transform maps a cell to its image under a rotation or mirror
+type transform func(row, col int) (int, int)
+
+// transforms lists the maps that generate each symmetry; a clue pattern has
+// the symmetry if every one of them leaves it unchanged
+var transforms = [...][]transform{
+	NoSymmetry:    nil,
+	Rotational180: {rotate180},
+	Rotational90:  {rotate90},
+	Horizontal:    {mirrorHorizontal},
+	Vertical:      {mirrorVertical},
+	Diagonal:      {mirrorDiagonal},
+	AntiDiagonal:  {mirrorAntiDiagonal},
+	Dihedral:      {rotate90, mirrorHorizontal},
+}
+
+func rotate180(row, col int) (int, int)          { return 8 - row, 8 - col }
+func rotate90(row, col int) (int, int)           { return col, 8 - row }
+func mirrorHorizontal(row, col int) (int, int)   { return 8 - row, col }
+func mirrorVertical(row, col int) (int, int)     { return row, 8 - col }
+func mirrorDiagonal(row, col int) (int, int)     { return col, row }
+func mirrorAntiDiagonal(row, col int) (int, int) { return 8 - col, 8 - row }
+
+// String returns the command-line name of the symmetry
+func (s Symmetry) String() string {
+	if s < 0 || s >= symmetryCount {
+		return fmt.Sprintf("Symmetry(%d)", int(s))
+	}
+	return symmetryNames[s]
+}
+
+// ParseSymmetry looks up a symmetry by its command-line name
+func ParseSymmetry(name string) (Symmetry, error) {
+	for i, symmetryName := range symmetryNames {
+		if symmetryName == name {
+			return Symmetry(i), nil
+		}
+	}
+	return 0, fmt.Errorf("Error: Unknown symmetry %q (expected one of %v)", name, symmetryNames)
+}
+
+// AllSymmetries returns every symmetry, NoSymmetry first
+func AllSymmetries() []Symmetry {
+	all := make([]Symmetry, symmetryCount)
+	for i := range all {
+		all[i] = Symmetry(i)
+	}
+	return all
+}
+
+// Orbit returns the cells that must be empty or filled together with
+// (row, col) under the symmetry, starting with (row, col) itself
+// Orbits have 1, 2, 4 or 8 cells depending on the symmetry and the cell
+func (s Symmetry) Orbit(row, col int) []validator.Cell {
+	orbit := []validator.Cell{{Row: row, Col: col}}
+	if s < 0 || s >= symmetryCount {
+		return orbit
+	}
+
+	// Apply every transform to every cell found so far until nothing is new
+	for i := 0; i < len(orbit); i++ {
+		for _, t := range transforms[s] {
+			r, c := t(orbit[i].Row, orbit[i].Col)
+			image := validator.Cell{Row: r, Col: c}
+			if !containsCell(orbit, image) {
+				orbit = append(orbit, image)
+			}
+		}
+	}
+	return orbit
+}
+
+// Holds checks if the clue positions of the board follow the symmetry
+// Only which cells are filled matters, not their digits
+func (s Symmetry) Holds(board *utils.Board) bool {
+	if s < 0 || s >= symmetryCount {
+		return false
+	}
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			for _, t := range transforms[s] {
+				r, c := t(row, col)
+				if (board[row][col] == 0) != (board[r][c] == 0) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// SymmetriesOf reports which symmetries the clue pattern of a board has
+// NoSymmetry is left out since every pattern has it
+func SymmetriesOf(board *utils.Board) []Symmetry {
+	var found []Symmetry
+	for _, s := range AllSymmetries()[1:] {
+		if s.Holds(board) {
+			found = append(found, s)
+		}
+	}
+	return found
+}
+
+// containsCell checks if cell is in cells
+func containsCell(cells []validator.Cell, cell validator.Cell) bool {
+	for _, c := range cells {
+		if c == cell {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package grader
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sudoku/logic"
+	"sudoku/solver"
+	"sudoku/utils"
+	"sudoku/validator"
+)
+
+// ErrNotUnique is returned for puzzles without exactly one solution,
+// which cannot be rated
+var ErrNotUnique = errors.New("Error: Puzzle does not have a unique solution")
+
+// ErrUnsupported is returned for jigsaw variants, whose boxes the techniques
+// do not know
+var ErrUnsupported = errors.New("Error: Cannot grade puzzles of this variant")
+
+// Level is a coarse difficulty tier
+type Level int
+
+const (
+	Easy       Level = iota // Hidden singles only
+	Medium                  // Naked singles and box/line intersections
+	Hard                    // Pairs, X-Wing, naked triples, Swordfish
+	Expert                  // Solvable by logic, but needs triples, wings or quads
+	Diabolical              // Logic alone gets stuck; guessing is required
+)
+
+// levelNames holds the display name of every level
+var levelNames = [...]string{"Easy", "Medium", "Hard", "Expert", "Diabolical"}
+
+// String returns the display name of the level
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel converts a level name such as "hard" (any case) to a Level
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("Error: Unknown level %q (expected one of %v)", name, levelNames)
+}
+
+// scores holds the SE-style rating of every technique, roughly following
+// the Sudoku Explainer scale
+var scores = map[logic.Technique]float64{
+	logic.HiddenSingle:     1.5,
+	logic.NakedSingle:      2.3,
+	logic.PointingPair:     2.6,
+	logic.BoxLineReduction: 2.8,
+	logic.NakedPair:        3.0,
+	logic.XWing:            3.2,
+	logic.HiddenPair:       3.4,
+	logic.NakedTriple:      3.6,
+	logic.Swordfish:        3.8,
+	logic.HiddenTriple:     4.0,
+	logic.XYWing:           4.2,
+	logic.XYZWing:          4.4,
+	logic.NakedQuad:        5.0,
+	logic.Jellyfish:        5.2,
+	logic.HiddenQuad:       5.4,
+}
+
+// guessScore is the rating of a puzzle that needs guessing; a little is
+// added on top for every guess the propagating solver has to make
+const guessScore = 6.0
+
+// Score returns the SE-style rating of a single technique
+func Score(t logic.Technique) float64 {
+	return scores[t]
+}
+
+// Rating is the difficulty of a puzzle
+type Rating struct {
+	Level      Level
+	Score      float64         // Rating of the hardest step (SE-like, 1.5-10.0)
+	Hardest    logic.Technique // Hardest technique logic used
+	NeedsGuess bool            // True if logic alone could not finish
+	Steps      int             // Number of logical steps made
+}
+
+// String formats the rating as "Level (score)"
+func (r Rating) String() string {
+	return fmt.Sprintf("%s (%.1f)", r.Level, r.Score)
+}
+
+// HardestTechnique names the hardest technique needed, "Guessing" when
+// logic got stuck, or "None" for a board that needed no steps
+func (r Rating) HardestTechnique() string {
+	switch {
+	case r.NeedsGuess && r.Steps > 0:
+		return "Guessing (after " + r.Hardest.String() + ")"
+	case r.NeedsGuess:
+		return "Guessing"
+	case r.Steps == 0:
+		return "None"
+	default:
+		return r.Hardest.String()
+	}
+}
+
+// Grade rates a puzzle by the techniques a human needs to solve it
+// Returns ErrNotUnique unless the puzzle has exactly one solution, and
+// logic.ErrInvalidBoard if the givens are inconsistent
+func Grade(board *utils.Board) (Rating, error) {
+	return GradeVariant(board, nil)
+}
+
+// GradeVariant is Grade for a puzzle with the extra rules of a variant
+// The techniques only know the classic rules, which still hold whatever is
+// added to them, so puzzles that need the variant's rules to make progress
+// rate as Diabolical
+// Returns ErrUnsupported for jigsaw variants: the techniques reason about
+// 3x3 boxes, and deductions from boxes the puzzle does not have are wrong
+func GradeVariant(board *utils.Board, variant *validator.Variant) (Rating, error) {
+	if variant.HasIrregularBoxes() {
+		return Rating{}, ErrUnsupported
+	}
+
+	result, err := logic.Solve(board)
+	if err != nil {
+		return Rating{}, err
+	}
+	if (&solver.Backtracker{Variant: variant}).CountSolutions(board, 2) != 1 {
+		return Rating{}, ErrNotUnique
+	}
+
+	rating := Rating{Steps: len(result.Steps)}
+	if hardest, ok := result.Hardest(); ok {
+		rating.Hardest = hardest
+		rating.Score = scores[hardest]
+	}
+
+	// Logic got stuck: rate by how much guessing the rest needs
+	if !result.Solved {
+		rating.NeedsGuess = true
+		rating.Level = Diabolical
+		rating.Score = guessScore + guessBonus(&result.Board, variant)
+		return rating, nil
+	}
+
+	rating.Level = levelFor(rating.Score)
+	return rating, nil
+}
+
+// levelFor maps a logic score to a level
+func levelFor(score float64) Level {
+	switch {
+	case score < 2.0:
+		return Easy
+	case score < 3.0:
+		return Medium
+	case score < 4.0:
+		return Hard
+	default:
+		return Expert
+	}
+}
+
+// guessBonus adds 0.1 per guess the propagating solver needs to finish the
+// board logic got stuck on, capped at 4.0 so scores stay within 10.0
+func guessBonus(board *utils.Board, variant *validator.Variant) float64 {
+	backend := &solver.Backtracker{Propagate: true, Variant: variant}
+	backend.Solve(board)
+
+	guesses := backend.Stats().Guesses
+	if guesses > 40 {
+		guesses = 40
+	}
+	return float64(guesses) / 10
+}
@@ -0,0 +1,52 @@
+package logic
+
+import (
+	"errors"
+	"sudoku/candidates"
+	"sudoku/utils"
+	"sudoku/validator"
+)
+
+// ErrSolved is returned by Hint when the board has no empty cells
+var ErrSolved = errors.New("Error: Board is already solved")
+
+// ErrNoHint is returned by Hint when no supported technique applies
+var ErrNoHint = errors.New("Error: No logical step found, the board needs guessing")
+
+// Hint returns the single easiest deduction available on a board in progress,
+// without solving any further
+// Candidates are computed from the filled cells, so an elimination step
+// refers to those pencil marks
+// Returns ErrInvalidBoard if the board breaks a rule, ErrSolved if it is
+// complete and ErrNoHint if no technique applies
+// The board is left unchanged
+func Hint(board *utils.Board) (Step, error) {
+	marks := candidates.Compute(board)
+	return HintFrom(board, &marks)
+}
+
+// HintFrom is Hint starting from the player's own pencil marks, so
+// eliminations they have already made are not suggested again
+// Marks the rules already rule out are ignored; marks the player rubbed out
+// by mistake can lead to wrong hints
+func HintFrom(board *utils.Board, marks *candidates.Grid) (Step, error) {
+	if len(validator.ValidateBoard(board)) > 0 {
+		return Step{}, ErrInvalidBoard
+	}
+
+	g := newGrid(board)
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			g.cands[row][col] &= marks[row][col]
+		}
+	}
+	if g.solved() {
+		return Step{}, ErrSolved
+	}
+
+	step, ok := g.nextStep()
+	if !ok {
+		return Step{}, ErrNoHint
+	}
+	return step, nil
+}
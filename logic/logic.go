@@ -0,0 +1,170 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"sudoku/candidates"
+	"sudoku/utils"
+	"sudoku/validator"
+)
+
+// ErrInvalidBoard is returned when the givens already break a sudoku rule
+var ErrInvalidBoard = errors.New("Error: Board breaks a sudoku rule")
+
+// Candidate is a digit at a cell, placed or eliminated by a step
+type Candidate struct {
+	Row, Col, Digit int
+}
+
+// String formats the candidate as "digit at (row, col)"
+func (c Candidate) String() string {
+	return fmt.Sprintf("%d at (%d, %d)", c.Digit, c.Row, c.Col)
+}
+
+// Step is one human-style deduction
+// A step either solves cells (Placements) or rules candidates out (Eliminations)
+type Step struct {
+	Technique    Technique
+	Placements   []Candidate      // Cells solved by this step
+	Eliminations []Candidate      // Candidates removed by this step
+	Cells        []validator.Cell // Cells forming the pattern that justifies the step
+	Description  string           // Human readable explanation
+}
+
+// String formats the step as "Technique: explanation"
+func (s Step) String() string {
+	return s.Technique.String() + ": " + s.Description
+}
+
+// Result is the outcome of solving a board with logic only
+type Result struct {
+	Board  utils.Board // Board after every deduction was applied
+	Steps  []Step      // Deductions in the order they were made
+	Solved bool        // True if no empty cells remain
+}
+
+// Techniques returns the distinct techniques used, easiest first
+func (r Result) Techniques() []Technique {
+	used := map[Technique]bool{}
+	for _, step := range r.Steps {
+		used[step.Technique] = true
+	}
+
+	var techniques []Technique
+	for t := Technique(0); t < techniqueCount; t++ {
+		if used[t] {
+			techniques = append(techniques, t)
+		}
+	}
+	return techniques
+}
+
+// Hardest returns the most difficult technique used
+// Returns false if no steps were needed
+func (r Result) Hardest() (Technique, bool) {
+	techniques := r.Techniques()
+	if len(techniques) == 0 {
+		return 0, false
+	}
+	return techniques[len(techniques)-1], true
+}
+
+// Solve solves the board using only human techniques, never guessing
+// Each deduction is recorded as a step; the easiest available technique
+// is always applied first
+// Returns ErrInvalidBoard if the givens are inconsistent
+// The board is left unchanged
+func Solve(board *utils.Board) (Result, error) {
+	if len(validator.ValidateBoard(board)) > 0 {
+		return Result{}, ErrInvalidBoard
+	}
+
+	g := newGrid(board)
+	var steps []Step
+	for {
+		step, ok := g.nextStep()
+		if !ok {
+			break // Solved, or no technique applies
+		}
+		g.apply(step)
+		steps = append(steps, step)
+	}
+
+	return Result{Board: g.board, Steps: steps, Solved: g.solved()}, nil
+}
+
+// grid is a board together with the pencil marks of its empty cells
+type grid struct {
+	board utils.Board
+	cands candidates.Grid
+}
+
+// newGrid computes the candidates of every empty cell from the sudoku rules
+func newGrid(board *utils.Board) *grid {
+	return &grid{board: *board, cands: candidates.Compute(board)}
+}
+
+// nextStep finds the easiest deduction available
+// Returns false if the board is solved or no technique applies
+func (g *grid) nextStep() (Step, bool) {
+	for _, f := range finders {
+		if step, ok := f.find(g); ok {
+			step.Technique = f.technique
+			return step, true
+		}
+	}
+	return Step{}, false
+}
+
+// apply makes the placements and eliminations of a step
+func (g *grid) apply(step Step) {
+	for _, p := range step.Placements {
+		g.place(p.Row, p.Col, p.Digit)
+	}
+	for _, e := range step.Eliminations {
+		g.cands.Remove(e.Row, e.Col, e.Digit)
+	}
+}
+
+// place fills a cell and removes its digit from the candidates of its peers
+func (g *grid) place(row, col, digit int) {
+	g.board[row][col] = digit
+	g.cands.Place(row, col, digit)
+}
+
+// has checks if digit is still a candidate of (row, col)
+func (g *grid) has(row, col, digit int) bool {
+	return g.cands.Has(row, col, digit)
+}
+
+// solved checks if every cell is filled
+func (g *grid) solved() bool {
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if g.board[row][col] == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// units holds all 27 units: rows 0-8, columns 9-17, boxes 18-26
+var units = validator.AllUnits()
+
+// unitKind returns "row", "column" or "box" for an index into units
+func unitKind(index int) string {
+	switch {
+	case index < 9:
+		return "row"
+	case index < 18:
+		return "column"
+	default:
+		return "box"
+	}
+}
+
+// unitName describes a unit by its index in units, e.g. "row 3"
+func unitName(index int) string {
+	return fmt.Sprintf("%s %d", unitKind(index), index%9)
+}
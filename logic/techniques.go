@@ -0,0 +1,681 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sudoku/candidates"
+	"sudoku/validator"
+)
+
+// Technique identifies a human solving technique
+// Techniques are ordered from easiest to hardest
+type Technique int
+
+const (
+	HiddenSingle Technique = iota
+	NakedSingle
+	PointingPair
+	BoxLineReduction
+	NakedPair
+	XWing
+	HiddenPair
+	NakedTriple
+	Swordfish
+	HiddenTriple
+	XYWing
+	XYZWing
+	NakedQuad
+	Jellyfish
+	HiddenQuad
+	techniqueCount
+)
+
+// techniqueNames holds the display name of every technique
+var techniqueNames = [techniqueCount]string{
+	HiddenSingle:     "Hidden Single",
+	NakedSingle:      "Naked Single",
+	PointingPair:     "Pointing Pair",
+	BoxLineReduction: "Box/Line Reduction",
+	NakedPair:        "Naked Pair",
+	XWing:            "X-Wing",
+	HiddenPair:       "Hidden Pair",
+	NakedTriple:      "Naked Triple",
+	Swordfish:        "Swordfish",
+	HiddenTriple:     "Hidden Triple",
+	XYWing:           "XY-Wing",
+	XYZWing:          "XYZ-Wing",
+	NakedQuad:        "Naked Quad",
+	Jellyfish:        "Jellyfish",
+	HiddenQuad:       "Hidden Quad",
+}
+
+// String returns the display name of the technique
+func (t Technique) String() string {
+	if t < 0 || t >= techniqueCount {
+		return fmt.Sprintf("Technique(%d)", int(t))
+	}
+	return techniqueNames[t]
+}
+
+// AllTechniques returns every technique, easiest first
+func AllTechniques() []Technique {
+	techniques := make([]Technique, 0, techniqueCount)
+	for t := Technique(0); t < techniqueCount; t++ {
+		techniques = append(techniques, t)
+	}
+	return techniques
+}
+
+// finders lists the technique detectors in the order they are tried
+var finders = []struct {
+	technique Technique
+	find      func(g *grid) (Step, bool)
+}{
+	{HiddenSingle, findHiddenSingle},
+	{NakedSingle, findNakedSingle},
+	{PointingPair, findPointing},
+	{BoxLineReduction, findBoxLine},
+	{NakedPair, func(g *grid) (Step, bool) { return findNakedSubset(g, 2) }},
+	{XWing, func(g *grid) (Step, bool) { return findFish(g, 2) }},
+	{HiddenPair, func(g *grid) (Step, bool) { return findHiddenSubset(g, 2) }},
+	{NakedTriple, func(g *grid) (Step, bool) { return findNakedSubset(g, 3) }},
+	{Swordfish, func(g *grid) (Step, bool) { return findFish(g, 3) }},
+	{HiddenTriple, func(g *grid) (Step, bool) { return findHiddenSubset(g, 3) }},
+	{XYWing, findXYWing},
+	{XYZWing, findXYZWing},
+	{NakedQuad, func(g *grid) (Step, bool) { return findNakedSubset(g, 4) }},
+	{Jellyfish, func(g *grid) (Step, bool) { return findFish(g, 4) }},
+	{HiddenQuad, func(g *grid) (Step, bool) { return findHiddenSubset(g, 4) }},
+}
+
+// findNakedSingle looks for an empty cell with a single candidate left
+// The justifying cells are filled peers holding each of the other digits
+func findNakedSingle(g *grid) (Step, bool) {
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if g.board[row][col] != 0 || g.cands[row][col].Count() != 1 {
+				continue
+			}
+			digit := g.cands[row][col].Digits()[0]
+			return Step{
+				Placements:  []Candidate{{row, col, digit}},
+				Cells:       otherDigitPeers(g, validator.Cell{Row: row, Col: col}, digit),
+				Description: fmt.Sprintf("(%d, %d) has only one candidate left: %d", row, col, digit),
+			}, true
+		}
+	}
+	return Step{}, false
+}
+
+// findHiddenSingle looks for a digit that fits only one cell of a unit
+// The justifying cells are filled cells holding the digit that rule it out
+// of the unit's other empty cells; when the cell is the last empty one of
+// the unit, they are its peers holding the other digits
+func findHiddenSingle(g *grid) (Step, bool) {
+	for index, unit := range units {
+		for digit := 1; digit <= 9; digit++ {
+			cells := cellsWith(g, unit, digit)
+			if len(cells) != 1 {
+				continue
+			}
+			cell := cells[0]
+			var because []validator.Cell
+			for _, other := range unit {
+				if other == cell || g.board[other.Row][other.Col] != 0 {
+					continue
+				}
+				if peer, ok := filledPeer(g, other, digit); ok && !containsCell(because, peer) {
+					because = append(because, peer)
+				}
+			}
+			if len(because) == 0 {
+				because = otherDigitPeers(g, cell, digit)
+			}
+			return Step{
+				Placements: []Candidate{{cell.Row, cell.Col, digit}},
+				Cells:      because,
+				Description: fmt.Sprintf("%d can only go in (%d, %d) in %s",
+					digit, cell.Row, cell.Col, unitName(index)),
+			}, true
+		}
+	}
+	return Step{}, false
+}
+
+// findPointing looks for a digit confined to one row or column within a box,
+// which removes it from the rest of that row or column
+func findPointing(g *grid) (Step, bool) {
+	for box := 0; box < 9; box++ {
+		for digit := 1; digit <= 9; digit++ {
+			cells := cellsWith(g, units[18+box], digit)
+			if len(cells) < 2 {
+				continue
+			}
+
+			// Try the shared row first, then the shared column
+			for _, line := range []int{sharedRow(cells), sharedCol(cells)} {
+				if line == -1 {
+					continue
+				}
+				var eliminations []Candidate
+				for _, cell := range units[line] {
+					if validator.BoxIndex(cell.Row, cell.Col) != box && g.has(cell.Row, cell.Col, digit) {
+						eliminations = append(eliminations, Candidate{cell.Row, cell.Col, digit})
+					}
+				}
+				if len(eliminations) > 0 {
+					return Step{
+						Eliminations: eliminations,
+						Cells:        cells,
+						Description: fmt.Sprintf("%d in box %d is confined to %s, so it is removed from the rest of the %s",
+							digit, box, unitName(line), unitKind(line)),
+					}, true
+				}
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// findBoxLine looks for a digit confined to one box within a row or column,
+// which removes it from the rest of that box
+func findBoxLine(g *grid) (Step, bool) {
+	for line := 0; line < 18; line++ {
+		for digit := 1; digit <= 9; digit++ {
+			cells := cellsWith(g, units[line], digit)
+			if len(cells) < 2 {
+				continue
+			}
+			box := sharedBox(cells)
+			if box == -1 {
+				continue
+			}
+
+			var eliminations []Candidate
+			for _, cell := range units[18+box] {
+				if !inLine(cell, line) && g.has(cell.Row, cell.Col, digit) {
+					eliminations = append(eliminations, Candidate{cell.Row, cell.Col, digit})
+				}
+			}
+			if len(eliminations) > 0 {
+				return Step{
+					Eliminations: eliminations,
+					Cells:        cells,
+					Description: fmt.Sprintf("%d in %s is confined to box %d, so it is removed from the rest of the box",
+						digit, unitName(line), box),
+				}, true
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// findNakedSubset looks for n cells of a unit that together hold exactly n
+// candidates; those digits are removed from the unit's other cells
+func findNakedSubset(g *grid, n int) (Step, bool) {
+	for index, unit := range units {
+		// Only cells with 2..n candidates can be part of the subset
+		var pool []validator.Cell
+		for _, cell := range unit {
+			count := g.cands[cell.Row][cell.Col].Count()
+			if count >= 2 && count <= n {
+				pool = append(pool, cell)
+			}
+		}
+
+		var found Step
+		ok := false
+		combinations(len(pool), n, func(picked []int) bool {
+			var union candidates.Set
+			subset := make([]validator.Cell, 0, n)
+			for _, i := range picked {
+				union |= g.cands[pool[i].Row][pool[i].Col]
+				subset = append(subset, pool[i])
+			}
+			if union.Count() != n {
+				return true
+			}
+
+			var eliminations []Candidate
+			for _, cell := range unit {
+				if containsCell(subset, cell) {
+					continue
+				}
+				for _, digit := range (g.cands[cell.Row][cell.Col] & union).Digits() {
+					eliminations = append(eliminations, Candidate{cell.Row, cell.Col, digit})
+				}
+			}
+			if len(eliminations) == 0 {
+				return true
+			}
+
+			found = Step{
+				Eliminations: eliminations,
+				Cells:        subset,
+				Description: fmt.Sprintf("%s in %s can only hold %s, so those digits are removed from the rest of the %s",
+					formatCells(subset), unitName(index), formatInts(union.Digits()),
+					unitKind(index)),
+			}
+			ok = true
+			return false
+		})
+		if ok {
+			return found, true
+		}
+	}
+	return Step{}, false
+}
+
+// findHiddenSubset looks for n digits confined to the same n cells of a unit;
+// every other candidate is removed from those cells
+func findHiddenSubset(g *grid, n int) (Step, bool) {
+	for index, unit := range units {
+		// Only digits with 2..n possible cells can be part of the subset
+		var pool []int
+		for digit := 1; digit <= 9; digit++ {
+			count := len(cellsWith(g, unit, digit))
+			if count >= 2 && count <= n {
+				pool = append(pool, digit)
+			}
+		}
+
+		var found Step
+		ok := false
+		combinations(len(pool), n, func(picked []int) bool {
+			var mask candidates.Set
+			var subset []validator.Cell
+			for _, i := range picked {
+				mask = mask.With(pool[i])
+				for _, cell := range cellsWith(g, unit, pool[i]) {
+					if !containsCell(subset, cell) {
+						subset = append(subset, cell)
+					}
+				}
+			}
+			if len(subset) != n {
+				return true
+			}
+
+			var eliminations []Candidate
+			for _, cell := range subset {
+				for _, digit := range (g.cands[cell.Row][cell.Col] &^ mask).Digits() {
+					eliminations = append(eliminations, Candidate{cell.Row, cell.Col, digit})
+				}
+			}
+			if len(eliminations) == 0 {
+				return true
+			}
+
+			found = Step{
+				Eliminations: eliminations,
+				Cells:        subset,
+				Description: fmt.Sprintf("%s can only go in %s in %s, so other candidates are removed from those cells",
+					formatInts(mask.Digits()), formatCells(subset), unitName(index)),
+			}
+			ok = true
+			return false
+		})
+		if ok {
+			return found, true
+		}
+	}
+	return Step{}, false
+}
+
+// findFish looks for n rows where a digit is confined to the same n columns
+// (X-Wing, Swordfish, Jellyfish), removing it from the rest of those columns;
+// the same is tried with rows and columns swapped
+func findFish(g *grid, n int) (Step, bool) {
+	for digit := 1; digit <= 9; digit++ {
+		for _, byRow := range []bool{true, false} {
+			// Base lines where the digit has 2..n possible positions
+			var base []int
+			var positions [][]validator.Cell
+			for line := 0; line < 9; line++ {
+				unit := units[line]
+				if !byRow {
+					unit = units[9+line]
+				}
+				cells := cellsWith(g, unit, digit)
+				if len(cells) >= 2 && len(cells) <= n {
+					base = append(base, line)
+					positions = append(positions, cells)
+				}
+			}
+
+			var found Step
+			ok := false
+			combinations(len(base), n, func(picked []int) bool {
+				// Collect the cover lines used by the picked base lines
+				covers := map[int]bool{}
+				var pattern []validator.Cell
+				baseLines := map[int]bool{}
+				for _, i := range picked {
+					baseLines[base[i]] = true
+					for _, cell := range positions[i] {
+						pattern = append(pattern, cell)
+						if byRow {
+							covers[cell.Col] = true
+						} else {
+							covers[cell.Row] = true
+						}
+					}
+				}
+				if len(covers) != n {
+					return true
+				}
+
+				// Remove the digit from the cover lines outside the base lines
+				var eliminations []Candidate
+				for cover := range covers {
+					unit := units[9+cover]
+					if !byRow {
+						unit = units[cover]
+					}
+					for _, cell := range unit {
+						line := cell.Row
+						if !byRow {
+							line = cell.Col
+						}
+						if !baseLines[line] && g.has(cell.Row, cell.Col, digit) {
+							eliminations = append(eliminations, Candidate{cell.Row, cell.Col, digit})
+						}
+					}
+				}
+				if len(eliminations) == 0 {
+					return true
+				}
+
+				baseKind, coverKind := "rows", "columns"
+				if !byRow {
+					baseKind, coverKind = "columns", "rows"
+				}
+				found = Step{
+					Eliminations: sortCandidates(eliminations),
+					Cells:        pattern,
+					Description: fmt.Sprintf("%d in %s %s is confined to %s %s, so it is removed from the rest of those %s",
+						digit, baseKind, formatInts(pickedLines(base, picked)),
+						coverKind, formatInts(sortedKeys(covers)), coverKind),
+				}
+				ok = true
+				return false
+			})
+			if ok {
+				return found, true
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// findXYWing looks for a pivot {x,y} seeing two pincers {x,z} and {y,z};
+// whichever pincer is true, z is placed, so z is removed from cells seeing both
+func findXYWing(g *grid) (Step, bool) {
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			pivot := g.cands[row][col]
+			if pivot.Count() != 2 {
+				continue
+			}
+			wings := bivaluePeers(g, row, col)
+			for i := 0; i < len(wings); i++ {
+				for j := i + 1; j < len(wings); j++ {
+					a, b := wings[i], wings[j]
+					ma, mb := g.cands[a.Row][a.Col], g.cands[b.Row][b.Col]
+
+					// The three masks must be the three pairs of one digit triple
+					if ma == mb || ma == pivot || mb == pivot ||
+						(pivot|ma|mb).Count() != 3 {
+						continue
+					}
+					z := (ma & mb &^ pivot).Digits()
+					if len(z) != 1 {
+						continue
+					}
+
+					eliminations := seenByAll(g, z[0], []validator.Cell{a, b},
+						[]validator.Cell{{Row: row, Col: col}, a, b})
+					if len(eliminations) > 0 {
+						return Step{
+							Eliminations: eliminations,
+							Cells:        []validator.Cell{{Row: row, Col: col}, a, b},
+							Description: fmt.Sprintf("pivot (%d, %d) with pincers (%d, %d) and (%d, %d) forces %d into a pincer, so it is removed from cells seeing both",
+								row, col, a.Row, a.Col, b.Row, b.Col, z[0]),
+						}, true
+					}
+				}
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// findXYZWing looks for a pivot {x,y,z} seeing pincers {x,z} and {y,z};
+// z is removed from cells seeing the pivot and both pincers
+func findXYZWing(g *grid) (Step, bool) {
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			pivot := g.cands[row][col]
+			if pivot.Count() != 3 {
+				continue
+			}
+			wings := bivaluePeers(g, row, col)
+			for i := 0; i < len(wings); i++ {
+				for j := i + 1; j < len(wings); j++ {
+					a, b := wings[i], wings[j]
+					ma, mb := g.cands[a.Row][a.Col], g.cands[b.Row][b.Col]
+					if ma == mb || ma|mb != pivot {
+						continue
+					}
+					z := (ma & mb).Digits()
+					if len(z) != 1 {
+						continue
+					}
+
+					pattern := []validator.Cell{{Row: row, Col: col}, a, b}
+					eliminations := seenByAll(g, z[0], pattern, pattern)
+					if len(eliminations) > 0 {
+						return Step{
+							Eliminations: eliminations,
+							Cells:        pattern,
+							Description: fmt.Sprintf("pivot (%d, %d) with pincers (%d, %d) and (%d, %d) forces %d into one of them, so it is removed from cells seeing all three",
+								row, col, a.Row, a.Col, b.Row, b.Col, z[0]),
+						}, true
+					}
+				}
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// bivaluePeers lists the peers of (row, col) with exactly two candidates
+func bivaluePeers(g *grid, row, col int) []validator.Cell {
+	var result []validator.Cell
+	for _, peer := range candidates.Peers(row, col) {
+		if g.cands[peer.Row][peer.Col].Count() == 2 {
+			result = append(result, peer)
+		}
+	}
+	return result
+}
+
+// seenByAll lists the candidates of digit in cells that see every cell in
+// watchers, skipping the cells in exclude
+func seenByAll(g *grid, digit int, watchers, exclude []validator.Cell) []Candidate {
+	var eliminations []Candidate
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			cell := validator.Cell{Row: row, Col: col}
+			if !g.has(row, col, digit) || containsCell(exclude, cell) {
+				continue
+			}
+			seesAll := true
+			for _, w := range watchers {
+				if !candidates.Sees(cell, w) {
+					seesAll = false
+					break
+				}
+			}
+			if seesAll {
+				eliminations = append(eliminations, Candidate{row, col, digit})
+			}
+		}
+	}
+	return eliminations
+}
+
+// otherDigitPeers lists a filled peer of cell for each digit other than
+// digit, the cells that leave digit as its only candidate
+func otherDigitPeers(g *grid, cell validator.Cell, digit int) []validator.Cell {
+	var cells []validator.Cell
+	for other := 1; other <= 9; other++ {
+		if other == digit {
+			continue
+		}
+		if peer, ok := filledPeer(g, cell, other); ok {
+			cells = append(cells, peer)
+		}
+	}
+	return cells
+}
+
+// filledPeer finds a filled peer of cell holding digit, the first in
+// reading order
+// Returns false if digit was ruled out of cell by a technique instead
+func filledPeer(g *grid, cell validator.Cell, digit int) (validator.Cell, bool) {
+	for _, peer := range candidates.Peers(cell.Row, cell.Col) {
+		if g.board[peer.Row][peer.Col] == digit {
+			return peer, true
+		}
+	}
+	return validator.Cell{}, false
+}
+
+// cellsWith lists the cells of a unit that still have digit as a candidate
+func cellsWith(g *grid, unit []validator.Cell, digit int) []validator.Cell {
+	var cells []validator.Cell
+	for _, cell := range unit {
+		if g.has(cell.Row, cell.Col, digit) {
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
+
+// sharedRow returns the units index of the row shared by all cells, or -1
+func sharedRow(cells []validator.Cell) int {
+	for _, cell := range cells[1:] {
+		if cell.Row != cells[0].Row {
+			return -1
+		}
+	}
+	return cells[0].Row
+}
+
+// sharedCol returns the units index of the column shared by all cells, or -1
+func sharedCol(cells []validator.Cell) int {
+	for _, cell := range cells[1:] {
+		if cell.Col != cells[0].Col {
+			return -1
+		}
+	}
+	return 9 + cells[0].Col
+}
+
+// sharedBox returns the box shared by all cells, or -1
+func sharedBox(cells []validator.Cell) int {
+	box := validator.BoxIndex(cells[0].Row, cells[0].Col)
+	for _, cell := range cells[1:] {
+		if validator.BoxIndex(cell.Row, cell.Col) != box {
+			return -1
+		}
+	}
+	return box
+}
+
+// inLine checks if a cell belongs to the row or column with the given units index
+func inLine(cell validator.Cell, line int) bool {
+	if line < 9 {
+		return cell.Row == line
+	}
+	return cell.Col == line-9
+}
+
+// containsCell checks if cell is in cells
+func containsCell(cells []validator.Cell, cell validator.Cell) bool {
+	for _, c := range cells {
+		if c == cell {
+			return true
+		}
+	}
+	return false
+}
+
+// combinations calls fn with every n-element subset of indexes 0..size-1,
+// in lexicographic order, until fn returns false
+func combinations(size, n int, fn func(picked []int) bool) {
+	picked := make([]int, n)
+	var recurse func(start, depth int) bool
+	recurse = func(start, depth int) bool {
+		if depth == n {
+			return fn(picked)
+		}
+		for i := start; i <= size-(n-depth); i++ {
+			picked[depth] = i
+			if !recurse(i+1, depth+1) {
+				return false
+			}
+		}
+		return true
+	}
+	recurse(0, 0)
+}
+
+// pickedLines returns the base line numbers chosen by a combination
+func pickedLines(base, picked []int) []int {
+	lines := make([]int, 0, len(picked))
+	for _, i := range picked {
+		lines = append(lines, base[i])
+	}
+	return lines
+}
+
+// sortedKeys returns the keys of a set in increasing order
+func sortedKeys(set map[int]bool) []int {
+	var keys []int
+	for i := 0; i < 9; i++ {
+		if set[i] {
+			keys = append(keys, i)
+		}
+	}
+	return keys
+}
+
+// sortCandidates orders candidates by row, then column
+func sortCandidates(candidates []Candidate) []Candidate {
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		return a.Row < b.Row || (a.Row == b.Row && a.Col < b.Col)
+	})
+	return candidates
+}
+
+// formatCells formats cells as "(r, c), (r, c)"
+func formatCells(cells []validator.Cell) string {
+	parts := make([]string, len(cells))
+	for i, cell := range cells {
+		parts[i] = fmt.Sprintf("(%d, %d)", cell.Row, cell.Col)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatInts formats numbers as a comma separated list
+func formatInts(list []int) string {
+	parts := make([]string, len(list))
+	for i, n := range list {
+		parts[i] = fmt.Sprint(n)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -1,30 +1,530 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"math/rand"
 	"os"
+	"strings"
+	"sudoku/candidates"
+	"sudoku/generator"
+	"sudoku/grader"
+	"sudoku/logic"
 	"sudoku/parser"
 	"sudoku/solver"
 	"sudoku/utils"
+	"sudoku/validator"
+	"time"
 )
 
 func main() {
-	// Get command-line arguments
-	args := os.Args[1:]
+	os.Exit(run(os.Args[1:], os.Stdout))
+}
+
+// run is the whole command: it reads the flags and arguments after the
+// program name and prints the result to stdout, diagnostics to stderr
+// Returns the exit status: 2 for flags that do not parse, 0 otherwise, as
+// every other failure prints Error
+func run(args []string, stdout io.Writer) int {
+	// Subcommands replace the solving flags and come first
+	if len(args) > 0 {
+		switch args[0] {
+		case "grade":
+			return runGrade(args[1:], stdout)
+		case "generate":
+			return runGenerate(args[1:], stdout)
+		case "hint":
+			return runHint(args[1:], stdout)
+		case "candidates":
+			return runCandidates(args[1:], stdout)
+		case "symmetry":
+			return runSymmetry(args[1:], stdout)
+		}
+	}
+
+	// Optional flags come before the nine row arguments
+	flags := flag.NewFlagSet("sudoku", flag.ContinueOnError)
+	unique := flags.Bool("unique", false, "print Error when the puzzle has more than one solution")
+	second := flags.Bool("second", false, "like -unique, but also print two solutions of an ambiguous puzzle")
+	solverName := flags.String("solver", "backtrack", "solving backend: backtrack, propagate or dlx")
+	strategyName := flags.String("strategy", "mrv", "cell selection strategy for backtracking backends: first, mrv or mrv-degree")
+	showStats := flags.Bool("stats", false, "print search statistics after the output")
+	timeout := flags.Duration("timeout", 0, "give up after this long, e.g. 500ms or 2s (0 means no limit)")
+	size := flags.String("size", "9", "grid size such as 4, 6, 12, 16 or 25, or box dimensions such as 2x3")
+	alphabet := flags.String("alphabet", "", "symbols for digits 1..size in order (default 1-9 then A, B, ...)")
+	variantOpts := addVariantFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return flagStatus(err)
+	}
+
+	args, variant, err := variantOpts.build(flags.Args())
+	if err != nil {
+		fmt.Fprintln(stdout, "Error")
+		fmt.Fprintln(os.Stderr, err)
+		return 0
+	}
+
+	// Other shapes and alphabets are only solved by DLX, with classic rules
+	shape, err := utils.ParseShape(*size)
+	generic := shape != utils.Classic || *alphabet != ""
+	if err == nil && variant != nil && generic {
+		err = errors.New("Error: Variants only apply to 9x9 boards with digits 1-9")
+	}
+	if err == nil && generic {
+		flags.Visit(func(f *flag.Flag) {
+			if err == nil && (f.Name == "solver" || f.Name == "strategy") {
+				err = fmt.Errorf("Error: -%s only applies to 9x9 boards with digits 1-9; other grids are solved with DLX", f.Name)
+			}
+		})
+	}
+	if err != nil {
+		fmt.Fprintln(stdout, "Error")
+		fmt.Fprintln(os.Stderr, err)
+		return 0
+	}
+
+	// Create the requested backend
+	backend, err := newSolver(*solverName, *strategyName, variant)
+	if err != nil {
+		fmt.Fprintln(stdout, "Error")
+		fmt.Fprintln(os.Stderr, err)
+		return 0
+	}
+
+	// Bound the search when a timeout was requested
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
 
-	// Parse the arguments into a board
+	// Uniqueness mode needs a second solution to prove a puzzle ambiguous
+	limit := 1
+	if *unique || *second {
+		limit = 2
+	}
+
+	// Other shapes and alphabets go through the generic grid solver
+	if generic {
+		runGrid(ctx, stdout, args, shape, *alphabet, limit, *second, *showStats)
+		return 0
+	}
+
+	// Parse the remaining arguments into a board
 	board, err := parser.ParseArgs(args)
 	if err != nil {
-		fmt.Println("Error")
-		return
+		fmt.Fprintln(stdout, "Error")
+		return 0
+	}
+
+	// Reject givens that already break a rule, explaining why on stderr
+	if conflicts := validator.ValidateVariant(&board, variant); len(conflicts) > 0 {
+		fmt.Fprintln(stdout, "Error")
+		for _, conflict := range conflicts {
+			fmt.Fprintln(os.Stderr, conflict)
+		}
+		return 0
 	}
 
 	// Attempt to solve sudoku
-	if !solver.Solve(&board) {
-		fmt.Println("Error")
+	solutions, err := backend.FindSolutionsContext(ctx, &board, limit)
+	if err != nil {
+		fmt.Fprintln(stdout, "Error")
+		fmt.Fprintln(os.Stderr, err)
+		if *showStats {
+			fmt.Fprintln(stdout, backend.Stats())
+		}
+		return 0
+	}
+
+	printSolutions(stdout, solutions, *second)
+
+	// Statistics come after the board so the solution format is unchanged
+	if *showStats {
+		fmt.Fprintln(stdout, backend.Stats())
+	}
+
+	return 0
+}
+
+// flagStatus is the exit status for flags that do not parse, as with
+// flag.ExitOnError: 0 after -h, 2 otherwise
+func flagStatus(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	return 2
+}
+
+// variantFlags holds the flags that add rules to a 9x9 puzzle
+type variantFlags struct {
+	names   *string
+	jigsaw  *bool
+	regions *string
+	cages   *string
+	dots    *string
+	clues   *string
+}
+
+// addVariantFlags defines the variant flags on a flag set, so solving and
+// grading read them the same way
+func addVariantFlags(flags *flag.FlagSet) variantFlags {
+	return variantFlags{
+		names:   flags.String("variant", "classic", "extra rules for 9x9 boards, comma separated: x, windoku, anti-knight, anti-king, non-consecutive"),
+		jigsaw:  flags.Bool("jigsaw", false, "the nine rows are followed by nine rows of region letters replacing the 3x3 boxes"),
+		regions: flags.String("regions", "", "file of nine rows of region letters replacing the 3x3 boxes"),
+		cages:   flags.String("cages", "", "file of killer cages, one \"sum: r1c1 r1c2 ...\" per line"),
+		dots:    flags.String("dots", "", "file of Kropki dots, one \"white|black r1c1 r1c2\" per line"),
+		clues:   flags.String("clues", "", "file of thermo, arrow and sandwich clues, such as \"thermo r1c1 r1c2\" or \"sandwich row 1 15\""),
+	}
+}
+
+// build combines every variant the flags ask for (nil for classic)
+// With -jigsaw, the region rows are taken from the end of args
+// Returns the remaining arguments and the variant
+func (f variantFlags) build(args []string) ([]string, *validator.Variant, error) {
+	variant, err := validator.ParseVariants(*f.names)
+	if err != nil {
+		return args, nil, err
+	}
+
+	// Irregular regions combine with any other variant
+	args, regions, err := jigsawRegions(args, *f.jigsaw, *f.regions)
+	if err != nil {
+		return args, nil, err
+	}
+	if regions != nil {
+		variant = validator.Combine(regions, variant)
+	}
+
+	// Cages, dots and line clues likewise, each read from its file
+	files := []struct {
+		path string
+		read func(path string) (*validator.Variant, error)
+	}{
+		{*f.cages, killerCages},
+		{*f.dots, kropkiDots},
+		{*f.clues, lineClues},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		extra, err := file.read(file.path)
+		if err != nil {
+			return args, nil, err
+		}
+		variant = validator.Combine(variant, extra)
+	}
+	return args, variant, nil
+}
+
+// jigsawRegions reads the region map of a jigsaw puzzle, either from the
+// nine arguments after the rows (inline) or from a file (path)
+// Returns the remaining arguments and the jigsaw variant, nil without a map
+func jigsawRegions(args []string, inline bool, path string) ([]string, *validator.Variant, error) {
+	var boxes [9][9]int
+	var err error
+	switch {
+	case inline && path != "":
+		return args, nil, errors.New("Error: Use either -jigsaw or -regions, not both")
+	case inline:
+		if len(args) != 18 {
+			return args, nil, errors.New("Error: Invalid number of arguments")
+		}
+		boxes, err = parser.ParseRegions(args[9:])
+		args = args[:9]
+	case path != "":
+		boxes, err = parser.ReadRegionsFile(path)
+	default:
+		return args, nil, nil
+	}
+	if err != nil {
+		return args, nil, err
+	}
+
+	variant, err := validator.Jigsaw(boxes)
+	return args, variant, err
+}
+
+// killerCages reads the cages of a killer puzzle from a file
+func killerCages(path string) (*validator.Variant, error) {
+	cages, err := parser.ReadCagesFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return validator.Killer(cages)
+}
+
+// kropkiDots reads the dots of a Kropki puzzle from a file
+func kropkiDots(path string) (*validator.Variant, error) {
+	dots, negative, err := parser.ReadDotsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return validator.Kropki(dots, negative)
+}
+
+// lineClues reads the thermos, arrows and sandwiches of a puzzle from a file
+func lineClues(path string) (*validator.Variant, error) {
+	clues, err := parser.ReadCluesFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return clues.Variant()
+}
+
+// runGrid solves a grid of any shape with the DLX solver, printing like the
+// classic board (one row per line, symbols separated by spaces)
+// Only DLX handles every shape, so run rejects -solver and -strategy here
+func runGrid(ctx context.Context, stdout io.Writer, args []string, shape utils.Shape, alphabet string, limit int, showSecond, showStats bool) {
+	grid, err := parser.ParseGrid(args, shape, alphabet)
+	if err != nil {
+		fmt.Fprintln(stdout, "Error")
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	// Reject givens that already break a rule, explaining why on stderr
+	if conflicts := validator.ValidateGrid(grid); len(conflicts) > 0 {
+		fmt.Fprintln(stdout, "Error")
+		for _, conflict := range conflicts {
+			fmt.Fprintln(os.Stderr, conflict)
+		}
 		return
 	}
 
-	// Print the solved board
-	utils.PrintBoard(&board)
+	backend := &solver.DLX{}
+	solutions, err := backend.FindGridSolutionsContext(ctx, grid, limit)
+	switch {
+	case err != nil:
+		fmt.Fprintln(stdout, "Error")
+		fmt.Fprintln(os.Stderr, err)
+	case len(solutions) == 1:
+		utils.FprintGrid(stdout, solutions[0])
+	default:
+		fmt.Fprintln(stdout, "Error")
+		if showSecond && len(solutions) == 2 {
+			utils.FprintGrid(stdout, solutions[0])
+			utils.FprintGrid(stdout, solutions[1])
+		}
+	}
+
+	if showStats {
+		fmt.Fprintln(stdout, backend.Stats())
+	}
+}
+
+// runGrade rates the puzzle given by the nine row arguments, printing
+// the level with its score and the hardest technique needed
+// The variant flags of the solver (-variant, -jigsaw and so on) come first
+func runGrade(args []string, stdout io.Writer) int {
+	flags := flag.NewFlagSet("grade", flag.ContinueOnError)
+	variantOpts := addVariantFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return flagStatus(err)
+	}
+
+	args, variant, err := variantOpts.build(flags.Args())
+	if err != nil {
+		fmt.Fprintln(stdout, "Error")
+		fmt.Fprintln(os.Stderr, err)
+		return 0
+	}
+
+	board, err := parser.ParseArgs(args)
+	if err != nil {
+		fmt.Fprintln(stdout, "Error")
+		return 0
+	}
+
+	rating, err := grader.GradeVariant(&board, variant)
+	if err != nil {
+		fmt.Fprintln(stdout, "Error")
+		fmt.Fprintln(os.Stderr, err)
+		return 0
+	}
+
+	fmt.Fprintln(stdout, "Rating:", rating)
+	fmt.Fprintln(stdout, "Hardest technique:", rating.HardestTechnique())
+	return 0
+}
+
+// runGenerate prints a new unique-solution puzzle, one row per line in the
+// format the solver reads, so its output can be passed straight back in
+// The seed and rating are reported on stderr so any puzzle can be
+// generated again
+func runGenerate(args []string, stdout io.Writer) int {
+	defaults := generator.DefaultOptions()
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	seed := flags.Int64("seed", 0, "random seed for a reproducible puzzle (0 picks one from the clock)")
+	minLevel := flags.String("min-level", defaults.MinLevel.String(), "easiest acceptable rating: easy, medium, hard, expert or diabolical")
+	maxLevel := flags.String("max-level", defaults.MaxLevel.String(), "hardest acceptable rating")
+	minGivens := flags.Int("min-givens", defaults.MinGivens, "stop removing clues at this many givens")
+	maxGivens := flags.Int("max-givens", defaults.MaxGivens, "reject puzzles with more givens than this")
+	symmetry := flags.String("symmetry", defaults.Symmetry.String(), "pattern of the given positions: none, 180, 90, horizontal, vertical, diagonal, anti-diagonal or dihedral")
+	attempts := flags.Int("attempts", defaults.MaxAttempts, "full grids to try before giving up")
+	variant := flags.String("variant", "classic", "extra rules, comma separated: x, windoku, anti-knight, anti-king, non-consecutive")
+	if err := flags.Parse(args); err != nil {
+		return flagStatus(err)
+	}
+
+	// Collect the options, rejecting unknown names
+	opts := defaults
+	opts.MinGivens, opts.MaxGivens, opts.MaxAttempts = *minGivens, *maxGivens, *attempts
+	var errs [4]error
+	opts.MinLevel, errs[0] = grader.ParseLevel(*minLevel)
+	opts.MaxLevel, errs[1] = grader.ParseLevel(*maxLevel)
+	opts.Symmetry, errs[2] = generator.ParseSymmetry(*symmetry)
+	opts.Variant, errs[3] = validator.ParseVariants(*variant)
+	for _, err := range errs {
+		if err != nil {
+			fmt.Fprintln(stdout, "Error")
+			fmt.Fprintln(os.Stderr, err)
+			return 0
+		}
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	fmt.Fprintln(os.Stderr, "Seed:", *seed)
+
+	puzzle, err := generator.GenerateWith(rand.New(rand.NewSource(*seed)), opts)
+	if err != nil {
+		fmt.Fprintln(stdout, "Error")
+		fmt.Fprintln(os.Stderr, err)
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "Rating: %v, %d givens\n", puzzle.Rating, puzzle.Givens)
+	for _, row := range utils.FormatRows(&puzzle.Board) {
+		fmt.Fprintln(stdout, row)
+	}
+	return 0
+}
+
+// runHint prints the easiest next deduction for the board given by the nine
+// row arguments: the technique and its explanation, then the cells it
+// solves or the candidates it removes, and the cells justifying it
+func runHint(args []string, stdout io.Writer) int {
+	board, err := parser.ParseArgs(args)
+	if err != nil {
+		fmt.Fprintln(stdout, "Error")
+		return 0
+	}
+
+	step, err := logic.Hint(&board)
+	if err != nil {
+		fmt.Fprintln(stdout, "Error")
+		fmt.Fprintln(os.Stderr, err)
+		return 0
+	}
+
+	fmt.Fprintln(stdout, step)
+	if len(step.Placements) > 0 {
+		fmt.Fprintln(stdout, "Place:", joinCandidates(step.Placements))
+	}
+	if len(step.Eliminations) > 0 {
+		fmt.Fprintln(stdout, "Eliminate:", joinCandidates(step.Eliminations))
+	}
+	if len(step.Cells) > 0 {
+		cells := make([]string, len(step.Cells))
+		for i, cell := range step.Cells {
+			cells[i] = fmt.Sprintf("(%d, %d)", cell.Row, cell.Col)
+		}
+		fmt.Fprintln(stdout, "Because of:", strings.Join(cells, ", "))
+	}
+	return 0
+}
+
+// joinCandidates formats candidates as a comma separated list
+func joinCandidates(candidates []logic.Candidate) string {
+	parts := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		parts[i] = candidate.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// runCandidates prints the pencil marks of the board given by the nine row
+// arguments as a 27x27 grid, each cell a 3x3 block of its candidates
+func runCandidates(args []string, stdout io.Writer) int {
+	board, err := parser.ParseArgs(args)
+	if err != nil {
+		fmt.Fprintln(stdout, "Error")
+		return 0
+	}
+
+	marks := candidates.Compute(&board)
+	candidates.Fprint(stdout, &board, &marks)
+	return 0
+}
+
+// runSymmetry prints the symmetries of the clue pattern given by the nine
+// row arguments, one per line, or "none" if it has none
+func runSymmetry(args []string, stdout io.Writer) int {
+	board, err := parser.ParseArgs(args)
+	if err != nil {
+		fmt.Fprintln(stdout, "Error")
+		return 0
+	}
+
+	symmetries := generator.SymmetriesOf(&board)
+	if len(symmetries) == 0 {
+		fmt.Fprintln(stdout, generator.NoSymmetry)
+	}
+	for _, symmetry := range symmetries {
+		fmt.Fprintln(stdout, symmetry)
+	}
+	return 0
+}
+
+// printSolutions prints the solution if there is exactly one
+// Several solutions (only searched for in uniqueness mode) print Error,
+// followed by the first two solutions when showSecond is set
+func printSolutions(stdout io.Writer, solutions []utils.Board, showSecond bool) {
+	switch len(solutions) {
+	case 0:
+		fmt.Fprintln(stdout, "Error")
+	case 1:
+		utils.FprintBoard(stdout, &solutions[0])
+	default:
+		fmt.Fprintln(stdout, "Error")
+		if showSecond {
+			utils.FprintBoard(stdout, &solutions[0])
+			utils.FprintBoard(stdout, &solutions[1])
+		}
+	}
+}
+
+// newSolver creates the named backend, applying the cell selection strategy
+// to backends that branch on cells, and the variant to backends that know it
+func newSolver(name, strategyName string, variant *validator.Variant) (solver.Solver, error) {
+	backend, err := solver.New(name)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy, err := solver.StrategyByName(strategyName)
+	if err != nil {
+		return nil, err
+	}
+	switch b := backend.(type) {
+	case *solver.Backtracker:
+		b.Strategy = strategy
+		b.Variant = variant
+	case *solver.DLX:
+		b.Variant = variant
+	default:
+		if variant != nil {
+			return nil, fmt.Errorf("Error: Solver %q does not support variants", name)
+		}
+	}
+
+	return backend, nil
 }
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureStderr captures what gets printed to stderr, like captureOutput in
+// the test package does for stdout
+func captureStderr(f func()) string {
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	f()
+
+	w.Close()
+	os.Stderr = oldStderr
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String()
+}
+
+// runCLI runs the command in process with args, returning stdout and stderr
+func runCLI(t *testing.T, args ...string) (string, string) {
+	t.Helper()
+	var stdout strings.Builder
+	var status int
+	stderr := captureStderr(func() { status = run(args, &stdout) })
+	if status != 0 {
+		t.Fatalf("run(%v) = %d, expected 0\n%s", args, status, stderr)
+	}
+	return stdout.String(), stderr
+}
+
+// windokuPuzzle is the output of "generate -variant windoku -seed 1", unique
+// only with the windows
+var windokuPuzzle = []string{"2......7.", "..5..1...", "........4", "..32.....", ".......5.", "...4.93..", "67..4....", "........9", ".....86.."}
+
+// TestCLI_GradeVariant verifies that grade applies the variant flags, so a
+// Windoku puzzle is rated rather than rejected as ambiguous
+func TestCLI_GradeVariant(t *testing.T) {
+	stdout, _ := runCLI(t, append([]string{"grade", "-variant", "windoku"}, windokuPuzzle...)...)
+	if !strings.HasPrefix(stdout, "Rating: ") {
+		t.Errorf("grade -variant windoku = %q, expected a rating", stdout)
+	}
+
+	stdout, stderr := runCLI(t, append([]string{"grade"}, windokuPuzzle...)...)
+	if stdout != "Error\n" || !strings.Contains(stderr, "unique") {
+		t.Errorf("grade (classic) = %q, %q, expected Error for a non-unique puzzle", stdout, stderr)
+	}
+
+	stdout, stderr = runCLI(t, append([]string{"grade", "-variant", "bogus"}, windokuPuzzle...)...)
+	if stdout != "Error\n" || !strings.Contains(stderr, "Unknown variant") {
+		t.Errorf("grade -variant bogus = %q, %q, expected an unknown variant error", stdout, stderr)
+	}
+}
+
+// TestCLI_SizeRejectsSolver verifies that -solver and -strategy are refused
+// for other grid sizes, which DLX always solves, rather than ignored
+func TestCLI_SizeRejectsSolver(t *testing.T) {
+	grid := []string{"1...", "..2.", ".3..", "...4"}
+	stdout, _ := runCLI(t, append([]string{"-size", "4"}, grid...)...)
+	if !strings.HasPrefix(stdout, "1 2 4 3\n3 4 2 1\n4 3 1 2\n2 1 3 4\n") {
+		t.Errorf("-size 4 = %q, expected the solved grid", stdout)
+	}
+
+	for _, flag := range [][]string{{"-solver", "propagate"}, {"-strategy", "first"}} {
+		args := append(append([]string{"-size", "4"}, flag...), grid...)
+		stdout, stderr := runCLI(t, args...)
+		if stdout != "Error\n" || !strings.Contains(stderr, flag[0]) {
+			t.Errorf("%v = %q, %q, expected Error naming %s", args, stdout, stderr, flag[0])
+		}
+	}
+}
+
+// TestCLI_BadFlag verifies that flags that do not parse give exit status 2,
+// as the flag package would on its own
+func TestCLI_BadFlag(t *testing.T) {
+	for _, args := range [][]string{{"-bogus"}, {"grade", "-bogus"}, {"generate", "-bogus"}} {
+		var stdout strings.Builder
+		var status int
+		stderr := captureStderr(func() { status = run(args, &stdout) })
+		if status != 2 || !strings.Contains(stderr, "bogus") {
+			t.Errorf("run(%v) = %d, %q, expected 2 and the flag named", args, status, stderr)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sudoku/utils"
+	"sudoku/validator"
+)
+
+// ParseCages reads killer cages, one per line, as the sum, a colon and the
+// cells in r1c1 notation (rows and columns numbered 1-9):
+//
+//	# Comments and blank lines are skipped
+//	3: r1c1 r1c2
+//	15: r1c3 r2c3 r3c3
+//
+// Returns error naming the line of the first malformed cage
+func ParseCages(text string) ([]validator.Cage, error) {
+	var cages []validator.Cage
+	for number, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Split the sum from the cell list
+		sumText, cellText, ok := strings.Cut(line, ":")
+		sum, err := strconv.Atoi(strings.TrimSpace(sumText))
+		if !ok || err != nil {
+			return nil, fmt.Errorf("Error: Invalid cage on line %d: expected \"sum: cells\"", number+1)
+		}
+
+		cage := validator.Cage{Sum: sum}
+		for _, field := range strings.Fields(cellText) {
+			cell, err := ParseCell(field)
+			if err != nil {
+				return nil, fmt.Errorf("%w on line %d", err, number+1)
+			}
+			cage.Cells = append(cage.Cells, cell)
+		}
+		cages = append(cages, cage)
+	}
+	return cages, nil
+}
+
+// ReadCagesFile reads killer cages from a file in the ParseCages format
+func ReadCagesFile(path string) ([]validator.Cage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCages(string(data))
+}
+
+// ParseCell converts r1c1 notation (case insensitive, 1-9) into a 0-based cell
+func ParseCell(text string) (validator.Cell, error) {
+	lower := strings.ToLower(text)
+	if len(lower) != 4 || lower[0] != 'r' || lower[2] != 'c' || !isDigit(lower[1]) || !isDigit(lower[3]) {
+		return validator.Cell{}, fmt.Errorf("Error: Invalid cell %q", text)
+	}
+	return validator.Cell{Row: utils.CharToInt(lower[1]) - 1, Col: utils.CharToInt(lower[3]) - 1}, nil
+}
+
+// isDigit checks if a character is one of 1-9
+func isDigit(c byte) bool {
+	return c >= '1' && c <= '9'
+}
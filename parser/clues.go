@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sudoku/validator"
+)
+
+// ParseClues reads thermometers, arrows and sandwich sums, one per line
+// A thermo lists its cells from the bulb to the tip, an arrow its circle
+// first, and a sandwich names a row or column (1-9) and its sum:
+//
+//	# Comments and blank lines are skipped
+//	thermo r1c1 r1c2 r1c3
+//	arrow r5c5 r4c4 r3c3
+//	sandwich row 1 15
+//	sandwich column 3 0
+//
+// Returns error naming the line of the first malformed clue
+func ParseClues(text string) (validator.Clues, error) {
+	var clues validator.Clues
+	for number, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		switch kind := strings.ToLower(fields[0]); {
+		case (kind == "thermo" || kind == "arrow") && len(fields) > 1:
+			var cells []validator.Cell
+			for _, field := range fields[1:] {
+				cell, err := ParseCell(field)
+				if err != nil {
+					return validator.Clues{}, fmt.Errorf("%w on line %d", err, number+1)
+				}
+				cells = append(cells, cell)
+			}
+			if kind == "thermo" {
+				clues.Thermos = append(clues.Thermos, cells)
+			} else {
+				clues.Arrows = append(clues.Arrows, validator.Arrow{Circle: cells[0], Cells: cells[1:]})
+			}
+
+		case kind == "sandwich" && len(fields) == 4:
+			line := strings.ToLower(fields[1])
+			index, indexErr := strconv.Atoi(fields[2])
+			sum, sumErr := strconv.Atoi(fields[3])
+			if (line != "row" && line != "column") || indexErr != nil || sumErr != nil {
+				return validator.Clues{}, fmt.Errorf("Error: Invalid sandwich on line %d: expected \"sandwich row|column number sum\"", number+1)
+			}
+			clues.Sandwiches = append(clues.Sandwiches, validator.Sandwich{Column: line == "column", Index: index - 1, Sum: sum})
+
+		default:
+			return validator.Clues{}, fmt.Errorf("Error: Invalid clue on line %d: expected \"thermo|arrow cells\" or \"sandwich row|column number sum\"", number+1)
+		}
+	}
+	return clues, nil
+}
+
+// ReadCluesFile reads line clues from a file in the ParseClues format
+func ReadCluesFile(path string) (validator.Clues, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return validator.Clues{}, err
+	}
+	return ParseClues(string(data))
+}
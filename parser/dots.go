@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sudoku/validator"
+)
+
+// ParseDots reads Kropki dots, one per line, as the colour and the two
+// neighbouring cells the dot sits between, in r1c1 notation
+// A line "negative" means neighbours without a dot are neither consecutive
+// nor one double the other:
+//
+//	# Comments and blank lines are skipped
+//	white r1c1 r1c2
+//	black r3c4 r4c4
+//	negative
+//
+// Returns the dots and whether the negative rule applies, or an error
+// naming the line of the first malformed dot
+func ParseDots(text string) ([]validator.Dot, bool, error) {
+	var dots []validator.Dot
+	negative := false
+	for number, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		colour := strings.ToLower(fields[0])
+		if colour == "negative" && len(fields) == 1 {
+			negative = true
+			continue
+		}
+		if (colour != "white" && colour != "black") || len(fields) != 3 {
+			return nil, false, fmt.Errorf("Error: Invalid dot on line %d: expected \"white|black cell cell\"", number+1)
+		}
+
+		dot := validator.Dot{Black: colour == "black"}
+		var err error
+		if dot.A, err = ParseCell(fields[1]); err == nil {
+			dot.B, err = ParseCell(fields[2])
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("%w on line %d", err, number+1)
+		}
+		dots = append(dots, dot)
+	}
+	return dots, negative, nil
+}
+
+// ReadDotsFile reads Kropki dots from a file in the ParseDots format
+func ReadDotsFile(path string) ([]validator.Dot, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	return ParseDots(string(data))
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"errors"
+	"strings"
 	"sudoku/utils"
 )
 
@@ -43,3 +44,53 @@ func ParseArgs(args []string) (utils.Board, error) {
 func isValidChar(c byte) bool {
 	return c == '.' || (c >= '1' && c <= '9')
 }
+
+// ParseGrid converts command-line arguments into a grid of any shape
+// There must be one argument per row, each with one symbol per cell
+// An empty alphabet uses utils.Symbols (1-9, then A, B, ...)
+// Returns error if input is invalid
+func ParseGrid(args []string, shape utils.Shape, alphabet string) (*utils.Grid, error) {
+	grid := utils.NewGrid(shape)
+	size := grid.Size()
+
+	// Check a custom alphabet has one distinct symbol per digit
+	if alphabet != "" {
+		if !isValidAlphabet(alphabet, size) {
+			return nil, errors.New("Error: Invalid alphabet")
+		}
+		grid.Alphabet = alphabet
+	}
+
+	if len(args) != size {
+		return nil, errors.New("Error: Invalid number of arguments")
+	}
+
+	for row := 0; row < size; row++ {
+		if len(args[row]) != size {
+			return nil, errors.New("Error: Invalid row length")
+		}
+
+		for col := 0; col < size; col++ {
+			value, ok := grid.Value(args[row][col])
+			if !ok {
+				return nil, errors.New("Error: Invalid character")
+			}
+			grid.Cells[row][col] = value
+		}
+	}
+
+	return grid, nil
+}
+
+// isValidAlphabet checks that an alphabet has size distinct symbols, none '.'
+func isValidAlphabet(alphabet string, size int) bool {
+	if len(alphabet) != size {
+		return false
+	}
+	for i := 0; i < len(alphabet); i++ {
+		if alphabet[i] == '.' || strings.IndexByte(alphabet[i+1:], alphabet[i]) >= 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// ParseRegions converts nine rows of region letters into a jigsaw region map
+// Any symbol can name a region; regions are numbered 0-8 in order of first
+// appearance, so "AAABBBCCC" and "111222333" give the same map
+// Returns error if there are not nine rows of nine symbols naming at most
+// nine regions (see validator.Jigsaw for the shape of each region)
+func ParseRegions(rows []string) ([9][9]int, error) {
+	var regions [9][9]int
+
+	// Step 1: Validate row count
+	if len(rows) != 9 {
+		return regions, errors.New("Error: Invalid number of region rows")
+	}
+
+	// Step 2: Number each letter as it is first seen
+	names := ""
+	for row := 0; row < 9; row++ {
+		if len(rows[row]) != 9 {
+			return regions, errors.New("Error: Invalid region row length")
+		}
+
+		for col := 0; col < 9; col++ {
+			index := strings.IndexByte(names, rows[row][col])
+			if index < 0 {
+				if len(names) == 9 {
+					return regions, errors.New("Error: Too many regions")
+				}
+				names += string(rows[row][col])
+				index = len(names) - 1
+			}
+			regions[row][col] = index
+		}
+	}
+
+	return regions, nil
+}
+
+// ReadRegionsFile reads a jigsaw region map from a file holding nine rows
+// of region letters, separated by any whitespace
+func ReadRegionsFile(path string) ([9][9]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return [9][9]int{}, err
+	}
+	return ParseRegions(strings.Fields(string(data)))
+}
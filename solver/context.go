@@ -0,0 +1,47 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"sudoku/utils"
+)
+
+// ErrTimeout is returned when a solve is stopped by its context's deadline
+// Other cancellations return the context's own error (e.g. context.Canceled)
+var ErrTimeout = errors.New("Error: Solver timed out")
+
+// ErrUnsupported is returned by backends asked to enforce rules of a variant
+// they cannot express, such as killer cage sums for DLX
+var ErrUnsupported = errors.New("Error: Solver does not support this variant")
+
+// checkInterval is how many search nodes pass between cancellation checks,
+// keeping the cost of ctx.Err() out of the hot path
+const checkInterval = 1024
+
+// SolveContext is Solve with cancellation: it fills the board with its
+// first solution unless ctx is done first
+// Returns false and ErrTimeout if the deadline passed before an answer was found
+func SolveContext(ctx context.Context, board *utils.Board) (bool, error) {
+	solutions, err := (&Backtracker{}).FindSolutionsContext(ctx, board, 1)
+	if err != nil {
+		return false, err
+	}
+	if len(solutions) == 0 {
+		return false, nil
+	}
+	*board = solutions[0]
+	return true, nil
+}
+
+// contextError converts a finished context into the error solvers return
+// Returns nil while ctx is still active
+func contextError(ctx context.Context) error {
+	switch err := ctx.Err(); {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout
+	default:
+		return err
+	}
+}
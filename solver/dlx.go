@@ -0,0 +1,451 @@
+package solver
+
+import (
+	"context"
+	"sudoku/utils"
+	"sudoku/validator"
+	"time"
+)
+
+// DLX solves boards as an exact cover problem with Knuth's Algorithm X,
+// using Dancing Links to cover and uncover matrix columns cheaply
+//
+// Every candidate placement (row, col, num) is a matrix row that covers
+// four constraint columns: the cell is filled, and num appears once in the
+// row, once in the column and once in the box. A solution is a set of
+// matrix rows covering every column exactly once
+//
+// With Variant set, each extra region adds a column per digit: regions of
+// nine cells must hold every digit once, smaller ones at most once
+// Region sums (killer cages), pairs (Kropki dots, thermos), arrows,
+// sandwiches and other constraints fail with ErrUnsupported
+// A DLX is not safe for concurrent use
+type DLX struct {
+	Variant *validator.Variant
+	stats   Stats
+}
+
+// Solve fills the board with its first solution
+// Returns false (leaving the board unchanged) if there is none
+func (d *DLX) Solve(board *utils.Board) bool {
+	solutions := d.FindSolutions(board, 1)
+	if len(solutions) == 0 {
+		return false
+	}
+	*board = solutions[0]
+	return true
+}
+
+// CountSolutions counts solutions up to limit (all if limit <= 0)
+func (d *DLX) CountSolutions(board *utils.Board, limit int) int {
+	count := 0
+	d.Enumerate(board, func(utils.Board) bool {
+		count++
+		return limit <= 0 || count < limit
+	})
+	return count
+}
+
+// FindSolutions returns up to limit solutions (all if limit <= 0)
+// The board is left unchanged
+func (d *DLX) FindSolutions(board *utils.Board, limit int) []utils.Board {
+	solutions, _ := d.FindSolutionsContext(context.Background(), board, limit)
+	return solutions
+}
+
+// FindSolutionsContext returns up to limit solutions (all if limit <= 0),
+// stopping early with ErrTimeout or ctx's error once ctx is done
+// The board is left unchanged
+func (d *DLX) FindSolutionsContext(ctx context.Context, board *utils.Board, limit int) ([]utils.Board, error) {
+	var solutions []utils.Board
+	err := d.EnumerateContext(ctx, board, func(solution utils.Board) bool {
+		solutions = append(solutions, solution)
+		return limit <= 0 || len(solutions) < limit
+	})
+	return solutions, err
+}
+
+// Enumerate calls visit with every solution of the board in turn
+// Enumeration stops early when visit returns false
+// The board is left unchanged
+func (d *DLX) Enumerate(board *utils.Board, visit func(solution utils.Board) bool) {
+	d.EnumerateContext(context.Background(), board, visit)
+}
+
+// EnumerateContext is Enumerate that also stops once ctx is done
+// Returns ErrTimeout or ctx's error if enumeration was cut short by ctx
+func (d *DLX) EnumerateContext(ctx context.Context, board *utils.Board, visit func(solution utils.Board) bool) error {
+	return d.enumerate(ctx, utils.GridFromBoard(board), d.Variant, func(solution *utils.Grid) bool {
+		result, _ := solution.Board()
+		return visit(result)
+	})
+}
+
+// FindGridSolutionsContext returns up to limit solutions (all if limit <= 0)
+// of a grid of any shape, stopping early once ctx is done
+// The grid is left unchanged
+func (d *DLX) FindGridSolutionsContext(ctx context.Context, g *utils.Grid, limit int) ([]*utils.Grid, error) {
+	var solutions []*utils.Grid
+	err := d.EnumerateGridContext(ctx, g, func(solution *utils.Grid) bool {
+		solutions = append(solutions, solution)
+		return limit <= 0 || len(solutions) < limit
+	})
+	return solutions, err
+}
+
+// EnumerateGridContext calls visit with every solution of a grid of any
+// shape, stopping when visit returns false or once ctx is done
+// Variants only apply to 9x9 boards and are ignored here
+// The grid is left unchanged
+func (d *DLX) EnumerateGridContext(ctx context.Context, g *utils.Grid, visit func(solution *utils.Grid) bool) error {
+	return d.enumerate(ctx, g, nil, visit)
+}
+
+// enumerate builds the exact cover matrix for a grid, plus the region
+// columns of a variant on a 9x9 grid, and visits every solution
+func (d *DLX) enumerate(ctx context.Context, g *utils.Grid, variant *validator.Variant, visit func(solution *utils.Grid) bool) error {
+	d.stats = Stats{}
+	start := time.Now()
+	defer func() { d.stats.Elapsed = time.Since(start) }()
+
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	// Sums, pairs and other constraints are not exact cover constraints;
+	// the backtracker handles them. They only apply to 9x9 boards
+	if g.Shape == utils.Classic && !variant.ExactCover() {
+		return ErrUnsupported
+	}
+
+	// Refuse inconsistent givens, like the backtracker
+	// A variant's boxes may replace the 3x3 boxes, so it is checked instead
+	if board, ok := g.Board(); ok && variant != nil {
+		if len(validator.ValidateVariant(&board, variant)) > 0 {
+			return nil
+		}
+	} else if len(validator.ValidateGrid(g)) > 0 {
+		return nil
+	}
+
+	geometry := newGeometry(g.Shape, variant)
+	m := newMatrix(geometry.columnCount() + geometry.regionColumnCount(variant))
+	var placements []placement
+
+	// Regions smaller than a unit only forbid repeats: their columns are
+	// optional and never chosen to branch on
+	if variant != nil {
+		for index, region := range variant.Regions {
+			if len(region.Cells) < geometry.size {
+				for num := 1; num <= geometry.size; num++ {
+					m.makeOptional(geometry.regionColumn(index, num))
+				}
+			}
+		}
+	}
+
+	// One matrix row per placement still possible on the grid
+	placed := usedDigits(g, geometry)
+	for row := 0; row < geometry.size; row++ {
+		for col := 0; col < geometry.size; col++ {
+			for num := 1; num <= geometry.size; num++ {
+				given := g.Cells[row][col]
+				if given != 0 && given != num {
+					continue // Givens only keep their own digit
+				}
+				if given == 0 && placed.has(geometry, row, col, num) {
+					continue // Ruled out by a given, never part of a solution
+				}
+				columns := geometry.columns(row, col, num)
+				for _, index := range variant.RegionsOf(row, col) {
+					columns = append(columns, geometry.regionColumn(index, num))
+				}
+				m.addRow(len(placements), columns)
+				placements = append(placements, placement{row, col, num})
+			}
+		}
+	}
+
+	// Translate each exact cover back into a filled grid
+	m.stats = &d.stats
+	return m.search(ctx, func(rows []int) bool {
+		solution := g.Clone()
+		for _, id := range rows {
+			p := placements[id]
+			solution.Cells[p.row][p.col] = p.num
+		}
+		return visit(solution)
+	})
+}
+
+// unitDigits records which digits the givens already place in each row, column
+// and box, bit n set for digit n (grids have at most 35 digits)
+type unitDigits struct {
+	rows, cols, boxes []uint64
+}
+
+// usedDigits collects the digits given in every unit of the grid, with
+// boxes laid out as in the geometry
+func usedDigits(g *utils.Grid, geometry geometry) unitDigits {
+	size := g.Size()
+	u := unitDigits{make([]uint64, size), make([]uint64, size), make([]uint64, size)}
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			if num := g.Cells[row][col]; num != 0 {
+				u.rows[row] |= 1 << num
+				u.cols[col] |= 1 << num
+				u.boxes[geometry.box(row, col)] |= 1 << num
+			}
+		}
+	}
+	return u
+}
+
+// has checks if num is already given in the row, column or box of (row, col)
+func (u unitDigits) has(geometry geometry, row, col, num int) bool {
+	mask := u.rows[row] | u.cols[col] | u.boxes[geometry.box(row, col)]
+	return mask&(1<<num) != 0
+}
+
+// Stats reports the work done by the most recent call
+func (d *DLX) Stats() Stats {
+	return d.stats
+}
+
+// placement is the candidate (row, col, num) behind one matrix row
+type placement struct {
+	row, col, num int
+}
+
+// geometry describes the grid shape the exact cover columns are built for
+type geometry struct {
+	size int                    // Digits per unit, and cells per side
+	box  func(row, col int) int // Box holding a cell
+}
+
+// newGeometry builds the exact cover layout for a grid shape
+// A variant on a 9x9 grid supplies its own boxes (jigsaw regions)
+func newGeometry(shape utils.Shape, variant *validator.Variant) geometry {
+	if variant != nil && shape == utils.Classic {
+		return geometry{size: 9, box: variant.BoxIndex}
+	}
+	return geometry{size: shape.Size(), box: shape.Box}
+}
+
+// columnCount returns the number of constraint columns: four families of size^2
+func (g geometry) columnCount() int {
+	return 4 * g.size * g.size
+}
+
+// regionColumnCount returns the number of extra columns for a variant's regions
+func (g geometry) regionColumnCount(variant *validator.Variant) int {
+	if variant == nil {
+		return 0
+	}
+	return len(variant.Regions) * g.size
+}
+
+// regionColumn returns the column recording that num appears in a variant
+// region; these follow the four standard families
+func (g geometry) regionColumn(index, num int) int {
+	return g.columnCount() + index*g.size + num - 1
+}
+
+// columns returns the constraint columns covered by placing num at (row, col)
+// Variant regions append further columns (see regionColumn)
+func (g geometry) columns(row, col, num int) []int {
+	n := g.size
+	digit := num - 1
+	box := g.box(row, col)
+
+	return []int{
+		0*n*n + row*n + col,   // Cell (row, col) is filled
+		1*n*n + row*n + digit, // Row contains num
+		2*n*n + col*n + digit, // Column contains num
+		3*n*n + box*n + digit, // Box contains num
+	}
+}
+
+// node is one 1 in the sparse exact cover matrix, linked in four directions
+type node struct {
+	left, right, up, down *node
+	column                *column
+	row                   int // Matrix row id (index into placements)
+}
+
+// column is the header of a matrix column, tracking how many 1s remain
+type column struct {
+	node
+	size int
+}
+
+// matrix is a sparse exact cover matrix built from dancing links
+type matrix struct {
+	root    column
+	columns []*column
+	stats   *Stats // Optional, counts the work done by search
+}
+
+// newMatrix creates a matrix with the given number of empty columns
+func newMatrix(count int) *matrix {
+	m := &matrix{columns: make([]*column, count)}
+	m.root.left, m.root.right = &m.root.node, &m.root.node
+
+	for i := range m.columns {
+		c := &column{}
+		c.column = c
+		c.up, c.down = &c.node, &c.node
+
+		// Insert the header at the end of the root list
+		c.left = m.root.left
+		c.right = &m.root.node
+		m.root.left.right = &c.node
+		m.root.left = &c.node
+
+		m.columns[i] = c
+	}
+	return m
+}
+
+// makeOptional unlinks a column from the header list, so it need not be
+// covered but is still never covered twice (a secondary column)
+func (m *matrix) makeOptional(index int) {
+	c := m.columns[index]
+	c.left.right = c.right
+	c.right.left = c.left
+	c.left, c.right = &c.node, &c.node
+}
+
+// addRow appends a matrix row with a 1 in each of the given columns
+func (m *matrix) addRow(id int, columns []int) {
+	var first *node
+	for _, index := range columns {
+		c := m.columns[index]
+		n := &node{column: c, row: id}
+
+		// Link vertically at the bottom of the column
+		n.up = c.up
+		n.down = &c.node
+		c.up.down = n
+		c.up = n
+		c.size++
+
+		// Link horizontally into the row's circular list
+		if first == nil {
+			first = n
+			n.left, n.right = n, n
+		} else {
+			n.left = first.left
+			n.right = first
+			first.left.right = n
+			first.left = n
+		}
+	}
+}
+
+// cover removes a column and every row that has a 1 in it
+func (m *matrix) cover(c *column) {
+	c.right.left = c.left
+	c.left.right = c.right
+	for i := c.down; i != &c.node; i = i.down {
+		for j := i.right; j != i; j = j.right {
+			j.down.up = j.up
+			j.up.down = j.down
+			j.column.size--
+		}
+	}
+}
+
+// uncover restores a column covered by cover, in exactly reverse order
+func (m *matrix) uncover(c *column) {
+	for i := c.up; i != &c.node; i = i.up {
+		for j := i.left; j != i; j = j.left {
+			j.column.size++
+			j.down.up = j
+			j.up.down = j
+		}
+	}
+	c.right.left = &c.node
+	c.left.right = &c.node
+}
+
+// search runs Algorithm X, calling visit with the row ids of each exact cover
+// Stops as soon as visit returns false, or with an error once ctx is done
+func (m *matrix) search(ctx context.Context, visit func(rows []int) bool) error {
+	var rows []int
+	var err error
+	var recurse func() bool
+	nodes := 0
+
+	recurse = func() bool {
+		nodes++
+		if m.stats != nil {
+			m.stats.Nodes++
+			if len(rows) > m.stats.MaxDepth {
+				m.stats.MaxDepth = len(rows)
+			}
+		}
+
+		// Check for cancellation every so often
+		if nodes%checkInterval == 0 {
+			if err = contextError(ctx); err != nil {
+				return false
+			}
+		}
+
+		// Base case: every column covered means a complete solution
+		if m.root.right == &m.root.node {
+			return visit(rows)
+		}
+
+		// Branch on the column with the fewest remaining 1s
+		best := m.root.right.column
+		for c := best.right; c != &m.root.node; c = c.right {
+			if c.column.size < best.size {
+				best = c.column
+			}
+		}
+		if best.size == 0 {
+			return true // Dead end, but keep searching elsewhere
+		}
+
+		m.cover(best)
+		for r := best.down; r != &best.node; r = r.down {
+			// A column with a single row left is a forced choice, not a guess
+			if m.stats != nil {
+				if best.size == 1 {
+					m.stats.Propagations++
+				} else {
+					m.stats.Guesses++
+				}
+			}
+
+			// Choose this row and cover every other column it satisfies
+			rows = append(rows, r.row)
+			for j := r.right; j != r; j = j.right {
+				m.cover(j.column)
+			}
+
+			keepGoing := recurse()
+
+			// Undo in reverse order before trying the next row
+			for j := r.left; j != r; j = j.left {
+				m.uncover(j.column)
+			}
+			rows = rows[:len(rows)-1]
+
+			if !keepGoing {
+				m.uncover(best)
+				return false
+			}
+			if m.stats != nil {
+				m.stats.Backtracks++
+			}
+		}
+		m.uncover(best)
+		return true
+	}
+
+	recurse()
+	return err
+}
@@ -0,0 +1,25 @@
+package solver
+
+import (
+	"context"
+	"sudoku/utils"
+)
+
+// SolveGrid fills a grid of any shape with its first solution, using DLX
+// Returns false (leaving the grid unchanged) if there is none
+func SolveGrid(g *utils.Grid) bool {
+	solutions, _ := (&DLX{}).FindGridSolutionsContext(context.Background(), g, 1)
+	if len(solutions) == 0 {
+		return false
+	}
+	g.Cells = solutions[0].Cells
+	return true
+}
+
+// CountGridSolutions counts the solutions of a grid of any shape, stopping
+// once limit is reached (all if limit <= 0)
+// The grid is left unchanged
+func CountGridSolutions(g *utils.Grid, limit int) int {
+	solutions, _ := (&DLX{}).FindGridSolutionsContext(context.Background(), g, limit)
+	return len(solutions)
+}
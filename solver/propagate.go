@@ -0,0 +1,78 @@
+package solver
+
+import "sudoku/validator"
+
+// fillSingles repeatedly places naked singles (cells with one candidate) and
+// hidden singles (digits with one possible cell in a unit) until none are left
+// Returns the cells it filled, and false if the board reached a contradiction
+func (s *search) fillSingles() ([]validator.Cell, bool) {
+	var filled []validator.Cell
+	board := s.tracker.Board()
+
+	for changed := true; changed; {
+		changed = false
+
+		// Naked singles: an empty cell with exactly one candidate
+		for row := 0; row < 9; row++ {
+			for col := 0; col < 9; col++ {
+				if board[row][col] != 0 {
+					continue
+				}
+				mask := s.tracker.Candidates(row, col)
+				switch validator.CountCandidates(mask) {
+				case 0:
+					return filled, false // No digit fits this cell
+				case 1:
+					s.tracker.Place(row, col, maskDigit(mask))
+					filled = append(filled, validator.Cell{Row: row, Col: col})
+					s.stats.Propagations++
+					changed = true
+				}
+			}
+		}
+
+		// Hidden singles: a missing digit that fits only one cell of a unit
+		for _, unit := range s.units {
+			for num := 1; num <= 9; num++ {
+				count, last := 0, validator.Cell{}
+				present := false
+				for _, cell := range unit {
+					value := board[cell.Row][cell.Col]
+					if value == num {
+						present = true
+						break
+					}
+					if value == 0 && s.tracker.CanPlace(cell.Row, cell.Col, num) {
+						count++
+						last = cell
+					}
+				}
+				if present {
+					continue
+				}
+
+				switch count {
+				case 0:
+					return filled, false // The unit can no longer hold num
+				case 1:
+					s.tracker.Place(last.Row, last.Col, num)
+					filled = append(filled, last)
+					s.stats.Propagations++
+					changed = true
+				}
+			}
+		}
+	}
+
+	return filled, true
+}
+
+// maskDigit returns the lowest digit set in a candidate mask (0 if empty)
+func maskDigit(mask uint16) int {
+	for num := 1; num <= 9; num++ {
+		if mask&(1<<num) != 0 {
+			return num
+		}
+	}
+	return 0
+}
@@ -0,0 +1,45 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Factory creates a fresh, ready-to-use solver backend
+type Factory func() Solver
+
+// backends maps registered backend names to their factories
+var backends = map[string]Factory{}
+
+// The built-in backends
+func init() {
+	Register("backtrack", func() Solver { return &Backtracker{} })
+	Register("propagate", func() Solver { return &Backtracker{Propagate: true} })
+	Register("dlx", func() Solver { return &DLX{} })
+}
+
+// Register makes a backend available to New under name
+// Registering the same name twice replaces the earlier factory
+func Register(name string, factory Factory) {
+	backends[name] = factory
+}
+
+// New creates the backend registered under name
+// Returns an error listing the valid names if the name is unknown
+func New(name string) (Solver, error) {
+	factory, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("Error: Unknown solver %q (expected one of %v)", name, Names())
+	}
+	return factory(), nil
+}
+
+// Names returns the registered backend names, sorted
+func Names() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
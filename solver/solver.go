@@ -1,39 +1,271 @@
+// Package solver fills in sudoku boards with backtracking (Backtracker) or
+// exact cover (DLX) backends
+// Only DLX solves a utils.Grid of another shape (see SolveGrid); the
+// Backtracker and its strategies work on the 9x9 utils.Board
 package solver
 
 import (
+	"context"
+	"fmt"
+	"math/rand"
 	"sudoku/utils"
 	"sudoku/validator"
+	"time"
 )
 
+// Solver is the common interface of the solving backends, so callers
+// can choose between them (see Register and New)
+type Solver interface {
+	// Solve fills the board with its first solution, reporting whether one exists
+	Solve(board *utils.Board) bool
+
+	// CountSolutions counts solutions, stopping at limit (all if limit <= 0)
+	CountSolutions(board *utils.Board, limit int) int
+
+	// FindSolutions returns up to limit solutions (all if limit <= 0)
+	FindSolutions(board *utils.Board, limit int) []utils.Board
+
+	// FindSolutionsContext is FindSolutions that gives up once ctx is done,
+	// returning the solutions found so far with ErrTimeout or ctx's error
+	FindSolutionsContext(ctx context.Context, board *utils.Board, limit int) ([]utils.Board, error)
+
+	// Stats reports the work done by the most recent call
+	Stats() Stats
+}
+
+// Stats counts the work a solver did on one call
+type Stats struct {
+	Nodes        int           // Search tree nodes visited
+	Guesses      int           // Trial placements where more than one option remained
+	Backtracks   int           // Choices undone because they led to a dead end
+	MaxDepth     int           // Deepest level of the search tree reached
+	Propagations int           // Forced placements (only one option left)
+	Elapsed      time.Duration // Wall time of the call
+}
+
+// String formats the stats one per line, for printing after a solution
+func (s Stats) String() string {
+	return fmt.Sprintf("Nodes:        %d\n"+
+		"Guesses:      %d\n"+
+		"Backtracks:   %d\n"+
+		"Max depth:    %d\n"+
+		"Propagations: %d\n"+
+		"Elapsed:      %v",
+		s.Nodes, s.Guesses, s.Backtracks, s.MaxDepth, s.Propagations, s.Elapsed)
+}
+
+// Both backends must keep satisfying Solver
+var (
+	_ Solver = &Backtracker{}
+	_ Solver = &DLX{}
+)
+
+// Backtracker is the recursive backtracking solver
+// Strategy chooses which empty cell to branch on; nil means MinRemaining
+// With Propagate set, forced cells (naked and hidden singles) are filled
+// at every node before branching
+// With Rand set, digits are tried in random order instead of 1-9, so
+// solving an empty board yields a random full grid
+// With Variant set, its extra regions are enforced too (nil is classic)
+// A Backtracker is not safe for concurrent use
+type Backtracker struct {
+	Strategy  Strategy
+	Propagate bool
+	Rand      *rand.Rand
+	Variant   *validator.Variant
+	stats     Stats
+}
+
 // Solve attempts to solve the sudoku board using backtracking
 // Returns true if solved successfully, false if unsolvable
+// Boards whose givens already break a rule are rejected without searching
 // Modifies the board in-place
 func Solve(board *utils.Board) bool {
-	// Find the next empty cell (value = 0)
-	row, col := utils.FindEmptyCell(board)
+	return (&Backtracker{}).Solve(board)
+}
+
+// CountSolutions counts the solutions of the board, stopping once limit is reached
+// A limit <= 0 counts every solution (slow for sparse boards)
+// The board is left unchanged
+func CountSolutions(board *utils.Board, limit int) int {
+	return (&Backtracker{}).CountSolutions(board, limit)
+}
+
+// FindSolutions returns up to limit solutions of the board in search order
+// A limit <= 0 collects every solution
+// Returns nil if the givens are inconsistent
+// The board is left unchanged
+func FindSolutions(board *utils.Board, limit int) []utils.Board {
+	return (&Backtracker{}).FindSolutions(board, limit)
+}
+
+// Solve fills the board with its first solution
+// Returns false (leaving the board unchanged) if there is none
+func (b *Backtracker) Solve(board *utils.Board) bool {
+	solutions := b.FindSolutions(board, 1)
+	if len(solutions) == 0 {
+		return false
+	}
+	*board = solutions[0]
+	return true
+}
+
+// CountSolutions counts solutions up to limit (all if limit <= 0)
+func (b *Backtracker) CountSolutions(board *utils.Board, limit int) int {
+	return len(b.FindSolutions(board, limit))
+}
+
+// FindSolutions returns up to limit solutions (all if limit <= 0)
+// The board is left unchanged
+func (b *Backtracker) FindSolutions(board *utils.Board, limit int) []utils.Board {
+	solutions, _ := b.FindSolutionsContext(context.Background(), board, limit)
+	return solutions
+}
+
+// FindSolutionsContext returns up to limit solutions (all if limit <= 0),
+// stopping early with ErrTimeout or ctx's error once ctx is done
+// The board is left unchanged
+func (b *Backtracker) FindSolutionsContext(ctx context.Context, board *utils.Board, limit int) ([]utils.Board, error) {
+	b.stats = Stats{}
+	start := time.Now()
+	defer func() { b.stats.Elapsed = time.Since(start) }()
+
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	// Refuse inconsistent givens, which the search itself never re-checks
+	if len(validator.ValidateVariant(board, b.Variant)) > 0 {
+		return nil, nil
+	}
+
+	strategy := b.Strategy
+	if strategy == nil {
+		strategy = MinRemaining
+	}
+
+	// Work on a copy so the caller's board is never modified
+	work := *board
+	s := &search{
+		ctx:       ctx,
+		tracker:   validator.NewVariantTracker(&work, b.Variant),
+		units:     b.Variant.Units(),
+		strategy:  strategy,
+		propagate: b.Propagate,
+		rand:      b.Rand,
+		limit:     limit,
+		stats:     &b.stats,
+	}
+	s.run(0)
+	return s.solutions, s.err
+}
+
+// Stats reports the work done by the most recent call
+func (b *Backtracker) Stats() Stats {
+	return b.stats
+}
+
+// search holds the state of one backtracking run
+type search struct {
+	ctx       context.Context
+	err       error // Set when ctx stopped the search
+	tracker   *validator.Tracker
+	units     [][]validator.Cell // Groups checked for hidden singles
+	strategy  Strategy
+	propagate bool
+	rand      *rand.Rand // Shuffles the digit order when set
+	limit     int
+	solutions []utils.Board
+	stats     *Stats
+}
+
+// run explores the search tree below depth, collecting every completed board
+// Returns true once limit solutions have been collected (or the context
+// is done) so the search can stop
+func (s *search) run(depth int) bool {
+	s.stats.Nodes++
+	if depth > s.stats.MaxDepth {
+		s.stats.MaxDepth = depth
+	}
+
+	// Check for cancellation every so often; stopping unwinds like a final solution
+	if s.stats.Nodes%checkInterval == 0 {
+		if s.err = contextError(s.ctx); s.err != nil {
+			return true
+		}
+	}
+
+	// Fill forced cells first; they are undone together when this node returns
+	var forced []validator.Cell
+	if s.propagate {
+		var ok bool
+		forced, ok = s.fillSingles()
+		defer s.undo(forced)
+		if !ok {
+			return false // Propagation found a contradiction
+		}
+	}
+
+	// Let the strategy choose the next empty cell
+	row, col := s.strategy(s.tracker)
 
 	// Base case: no empty cells means board is complete
 	if row == -1 {
-		return true
+		s.solutions = append(s.solutions, *s.tracker.Board())
+		return s.limit > 0 && len(s.solutions) >= s.limit
 	}
 
-	// Try placing numbers 1-9
-	for num := 1; num <= 9; num++ {
-		// Check if this number is valid at this position
-		if validator.IsValid(board, row, col, num) {
-			// Place the number
-			board[row][col] = num
-
-			// Recursively attempt to solve the rest of the board
-			if Solve(board) {
-				return true // Solution found!
-			}
-
-			// Backtrack: remove the number and try next
-			board[row][col] = 0
+	// Try every digit still available for this cell
+	candidates := s.tracker.Candidates(row, col)
+	forcedCell := validator.CountCandidates(candidates) == 1
+	digits, count := s.order(candidates)
+	for _, num := range digits[:count] {
+		if forcedCell {
+			s.stats.Propagations++
+		} else {
+			s.stats.Guesses++
+		}
+
+		// Place the number and recurse into the rest of the board
+		s.tracker.Place(row, col, num)
+		done := s.run(depth + 1)
+
+		// Backtrack: always undo so the board is restored on the way out
+		s.tracker.Remove(row, col)
+		if done {
+			return true
 		}
+		s.stats.Backtracks++
 	}
 
 	// All numbers failed - this path is a dead end
 	return false
 }
+
+// order lists the digits of a candidate mask, ascending or shuffled if the
+// search has a random source
+// Returns a fixed array and its length so no allocation is made per node
+func (s *search) order(candidates uint16) ([9]int, int) {
+	var digits [9]int
+	count := 0
+	for num := 1; num <= 9; num++ {
+		if candidates&(1<<num) != 0 {
+			digits[count] = num
+			count++
+		}
+	}
+
+	if s.rand != nil {
+		s.rand.Shuffle(count, func(i, j int) {
+			digits[i], digits[j] = digits[j], digits[i]
+		})
+	}
+	return digits, count
+}
+
+// undo clears cells filled by propagation, most recent first
+func (s *search) undo(cells []validator.Cell) {
+	for i := len(cells) - 1; i >= 0; i-- {
+		s.tracker.Remove(cells[i].Row, cells[i].Col)
+	}
+}
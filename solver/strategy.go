@@ -0,0 +1,148 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+	"sudoku/utils"
+	"sudoku/validator"
+)
+
+// Strategy picks the next empty cell for the backtracker to branch on
+// Returns (-1, -1) if the board has no empty cells
+type Strategy func(t *validator.Tracker) (row, col int)
+
+// strategies maps the names accepted by StrategyByName to their functions
+var strategies = map[string]Strategy{
+	"first":      FirstEmpty,
+	"mrv":        MinRemaining,
+	"mrv-degree": MinRemainingDegree,
+}
+
+// StrategyByName looks up a strategy by its command-line name
+// Returns an error listing the valid names if the name is unknown
+func StrategyByName(name string) (Strategy, error) {
+	strategy, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("Error: Unknown strategy %q (expected one of %v)", name, StrategyNames())
+	}
+	return strategy, nil
+}
+
+// StrategyNames returns the names accepted by StrategyByName, sorted
+func StrategyNames() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FirstEmpty picks the first empty cell in reading order
+// This is the classic naive backtracker behaviour
+func FirstEmpty(t *validator.Tracker) (int, int) {
+	return utils.FindEmptyCell(t.Board())
+}
+
+// MinRemaining picks the empty cell with the fewest candidates
+// (minimum remaining values), so dead ends are found as early as possible
+// Ties are broken in reading order
+func MinRemaining(t *validator.Tracker) (int, int) {
+	board := t.Board()
+	bestRow, bestCol, best := -1, -1, 10
+
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if board[row][col] != 0 {
+				continue
+			}
+			count := validator.CountCandidates(t.Candidates(row, col))
+			if count < best {
+				bestRow, bestCol, best = row, col, count
+
+				// A cell with no candidates is a dead end - no need to look further
+				if count == 0 {
+					return bestRow, bestCol
+				}
+			}
+		}
+	}
+
+	return bestRow, bestCol
+}
+
+// MinRemainingDegree picks the empty cell with the fewest candidates, breaking
+// ties by the most empty peers (degree), the cell that constrains the most others
+func MinRemainingDegree(t *validator.Tracker) (int, int) {
+	board := t.Board()
+	bestRow, bestCol, best, bestDegree := -1, -1, 10, -1
+
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if board[row][col] != 0 {
+				continue
+			}
+			count := validator.CountCandidates(t.Candidates(row, col))
+			if count == 0 {
+				return row, col
+			}
+			if count > best {
+				continue
+			}
+
+			degree := emptyPeers(t, row, col)
+			if count < best || degree > bestDegree {
+				bestRow, bestCol, best, bestDegree = row, col, count, degree
+			}
+		}
+	}
+
+	return bestRow, bestCol
+}
+
+// emptyPeers counts the empty cells sharing a row, column, box or extra
+// region of the tracker's variant with (row, col), each cell once
+// Jigsaw variants use their regions as boxes
+func emptyPeers(t *validator.Tracker, row, col int) int {
+	board := t.Board()
+	variant := t.Variant()
+	var seen [9][9]bool
+	seen[row][col] = true
+	count := 0
+	visit := func(r, c int) {
+		if !seen[r][c] {
+			seen[r][c] = true
+			if board[r][c] == 0 {
+				count++
+			}
+		}
+	}
+
+	// Row and column peers
+	for i := 0; i < 9; i++ {
+		visit(row, i)
+		visit(i, col)
+	}
+
+	// Box peers
+	if variant.HasIrregularBoxes() {
+		for _, cell := range variant.BoxCells(variant.BoxIndex(row, col)) {
+			visit(cell.Row, cell.Col)
+		}
+	} else {
+		boxRow := (row / 3) * 3
+		boxCol := (col / 3) * 3
+		for i := 0; i < 9; i++ {
+			visit(boxRow+i/3, boxCol+i%3)
+		}
+	}
+
+	// Peers through diagonals, windows, cages and the other extra regions
+	for _, index := range variant.RegionsOf(row, col) {
+		for _, cell := range variant.Regions[index].Cells {
+			visit(cell.Row, cell.Col)
+		}
+	}
+
+	return count
+}
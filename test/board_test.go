@@ -1,6 +1,7 @@
 package test
 
 import (
+	"sudoku/parser"
 	"sudoku/utils"
 	"testing"
 )
@@ -193,3 +194,83 @@ func TestPrintBoard(t *testing.T) {
 		}
 	})
 }
+
+// TestFormatRows verifies that formatted rows parse back to the same board
+func TestFormatRows(t *testing.T) {
+	rows := utils.FormatRows(&examplePuzzle)
+	if rows[0] != ".96.4...1" {
+		t.Errorf("FormatRows()[0] = %q, expected %q", rows[0], ".96.4...1")
+	}
+
+	board, err := parser.ParseArgs(rows)
+	if err != nil {
+		t.Fatalf("ParseArgs(FormatRows()) unexpected error: %v", err)
+	}
+	if board != examplePuzzle {
+		t.Errorf("ParseArgs(FormatRows()) did not round-trip the board")
+	}
+}
+
+// TestParseShape verifies grid sizes and explicit box dimensions
+func TestParseShape(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected utils.Shape
+	}{
+		{"4", utils.Shape{BoxRows: 2, BoxCols: 2}},
+		{"6", utils.Shape{BoxRows: 2, BoxCols: 3}},
+		{"9", utils.Classic},
+		{"12", utils.Shape{BoxRows: 3, BoxCols: 4}},
+		{"16", utils.Shape{BoxRows: 4, BoxCols: 4}},
+		{"25", utils.Shape{BoxRows: 5, BoxCols: 5}},
+		{"3x2", utils.Shape{BoxRows: 3, BoxCols: 2}},
+	}
+	for _, test := range tests {
+		shape, err := utils.ParseShape(test.input)
+		if err != nil || shape != test.expected {
+			t.Errorf("ParseShape(%q) = %v, %v, expected %v", test.input, shape, err, test.expected)
+		}
+	}
+
+	for _, input := range []string{"7", "0", "abc", "2x", "6x6"} {
+		if _, err := utils.ParseShape(input); err == nil {
+			t.Errorf("ParseShape(%q) expected an error", input)
+		}
+	}
+}
+
+// TestGrid_Board verifies copying between boards and 9x9 grids, and the
+// symbols of larger grids
+func TestGrid_Board(t *testing.T) {
+	grid := utils.GridFromBoard(&examplePuzzle)
+	board, ok := grid.Board()
+	if !ok || board != examplePuzzle {
+		t.Errorf("GridFromBoard().Board() did not round-trip the board")
+	}
+	if _, ok := utils.NewGrid(utils.Shape{BoxRows: 2, BoxCols: 2}).Board(); ok {
+		t.Errorf("Board() on a 4x4 grid expected false")
+	}
+
+	hex := utils.NewGrid(utils.Shape{BoxRows: 4, BoxCols: 4})
+	if hex.Alphabet != "123456789ABCDEFG" || hex.Symbol(16) != 'G' || hex.Symbol(0) != '.' {
+		t.Errorf("16x16 alphabet = %q, expected 1-9 then A-G", hex.Alphabet)
+	}
+	if value, ok := hex.Value('A'); !ok || value != 10 {
+		t.Errorf("Value('A') = %d, %v, expected 10", value, ok)
+	}
+}
+
+// TestPrintGrid verifies that grids print like boards, with their symbols
+func TestPrintGrid(t *testing.T) {
+	grid := utils.NewGrid(utils.Shape{BoxRows: 2, BoxCols: 2})
+	grid.Cells[0] = []int{1, 2, 3, 4}
+	grid.Alphabet = "ABCD"
+
+	actual := captureOutput(func() {
+		utils.PrintGrid(grid)
+	})
+	expected := "A B C D\n. . . .\n. . . .\n. . . .\n\n"
+	if actual != expected {
+		t.Errorf("PrintGrid() = %q, expected %q", actual, expected)
+	}
+}
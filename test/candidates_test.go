@@ -0,0 +1,129 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"sudoku/candidates"
+	"sudoku/utils"
+	"sudoku/validator"
+	"testing"
+)
+
+// TestSet verifies adding, removing, counting and listing digits
+func TestSet(t *testing.T) {
+	set := candidates.NewSet(1, 5, 9)
+	if !set.Has(5) || set.Has(4) {
+		t.Errorf("NewSet(1, 5, 9).Has() wrong for 5 or 4")
+	}
+
+	set = set.With(4).Without(1)
+	if set.Count() != 3 || set.String() != "459" {
+		t.Errorf("Set = %v with %d digits, expected 459 with 3", set, set.Count())
+	}
+	if candidates.All.Count() != 9 || candidates.Set(0).String() != "." {
+		t.Errorf("All or empty set formatted wrongly")
+	}
+}
+
+// TestCompute verifies that candidates follow the validator rules and that
+// filled cells have none
+func TestCompute(t *testing.T) {
+	board := examplePuzzle
+	grid := candidates.Compute(&board)
+
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			for digit := 1; digit <= 9; digit++ {
+				expected := board[row][col] == 0 && validator.IsValid(&board, row, col, digit)
+				if grid.Has(row, col, digit) != expected {
+					t.Errorf("Compute() (%d, %d) has %d = %v, expected %v",
+						row, col, digit, grid.Has(row, col, digit), expected)
+				}
+			}
+		}
+	}
+	if board != examplePuzzle {
+		t.Errorf("Compute() modified the input board")
+	}
+}
+
+// TestGrid_Edit verifies pencilling in, rubbing out and placing digits
+func TestGrid_Edit(t *testing.T) {
+	var grid candidates.Grid
+	grid.Add(0, 0, 3)
+	grid.Add(0, 0, 7)
+	if grid[0][0] != candidates.NewSet(3, 7) {
+		t.Errorf("Add() cell = %v, expected 37", grid[0][0])
+	}
+	if !grid.Remove(0, 0, 3) || grid.Remove(0, 0, 3) {
+		t.Errorf("Remove() should report true once, then false")
+	}
+
+	grid = candidates.Compute(&utils.Board{})
+	grid.Place(4, 4, 5)
+	if grid[4][4] != 0 {
+		t.Errorf("Place() left candidates in the placed cell: %v", grid[4][4])
+	}
+	for _, peer := range candidates.Peers(4, 4) {
+		if grid.Has(peer.Row, peer.Col, 5) {
+			t.Errorf("Place() left 5 in peer (%d, %d)", peer.Row, peer.Col)
+		}
+	}
+	if !grid.Has(0, 0, 5) || len(candidates.Peers(4, 4)) != 20 {
+		t.Errorf("Place() touched a cell that is not a peer, or Peers() is not 20 cells")
+	}
+}
+
+// TestGrid_StringParse verifies that serialised grids parse back unchanged
+func TestGrid_StringParse(t *testing.T) {
+	grid := candidates.Compute(&examplePuzzle)
+	text := grid.String()
+	if lines := strings.Split(text, "\n"); len(lines) != 9 || !strings.HasPrefix(lines[0], "2378 . . ") {
+		t.Errorf("Grid.String() first line = %q, expected 9 lines starting %q", lines[0], "2378 . . ")
+	}
+
+	parsed, err := candidates.Parse(text)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if parsed != grid {
+		t.Errorf("Parse(Grid.String()) did not round-trip")
+	}
+}
+
+// TestParse_Errors verifies that malformed pencil marks are rejected
+func TestParse_Errors(t *testing.T) {
+	if _, err := candidates.Parse("12 3 ."); !errors.Is(err, candidates.ErrInvalidCount) {
+		t.Errorf("Parse(3 cells) error = %v, expected ErrInvalidCount", err)
+	}
+	fields := strings.Fields(strings.Repeat(". ", 81))
+	fields[10] = "1x"
+	if _, err := candidates.Parse(strings.Join(fields, " ")); !errors.Is(err, candidates.ErrInvalidCandidate) {
+		t.Errorf("Parse(\"1x\") error = %v, expected ErrInvalidCandidate", err)
+	}
+}
+
+// TestFormat verifies the 27x27 drawing of empty and filled cells
+func TestFormat(t *testing.T) {
+	board := examplePuzzle
+	grid := candidates.Compute(&board)
+	lines := candidates.Format(&board, &grid)
+
+	if len(lines) != 27 {
+		t.Fatalf("Format() returned %d lines, expected 27", len(lines))
+	}
+	for i, line := range lines {
+		if len(line) != 27 {
+			t.Errorf("Format() line %d has %d characters, expected 27", i, len(line))
+		}
+	}
+
+	// (0, 0) holds 2, 3, 7 and 8; (0, 1) is a given 9
+	expected := []string{".23", "...", "78."}
+	filled := []string{"   ", " 9 ", "   "}
+	for i := 0; i < 3; i++ {
+		if lines[i][0:3] != expected[i] || lines[i][3:6] != filled[i] {
+			t.Errorf("Format() line %d starts %q, expected %q", i, lines[i][0:6], expected[i]+filled[i])
+		}
+	}
+}
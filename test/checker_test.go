@@ -0,0 +1,100 @@
+package test
+
+import (
+	"errors"
+	"sudoku/checker"
+	"sudoku/solver"
+	"sudoku/utils"
+	"testing"
+)
+
+// TestCheck_CorrectProgress verifies that a partly filled board with only
+// correct entries, and the full solution, report no errors
+func TestCheck_CorrectProgress(t *testing.T) {
+	solution := examplePuzzle
+	solver.Solve(&solution)
+
+	player := examplePuzzle
+	player[0][0] = solution[0][0]
+	player[8][8] = solution[8][8]
+
+	for _, board := range []utils.Board{examplePuzzle, player, solution} {
+		report, err := checker.Check(&examplePuzzle, &board)
+		if err != nil {
+			t.Fatalf("Check() unexpected error: %v", err)
+		}
+		if !report.OK() {
+			t.Errorf("Check() = %+v, expected no errors", report)
+		}
+	}
+}
+
+// TestCheck_MistakeWithoutConflict verifies that an entry which breaks no
+// rule but contradicts the solution is reported as a mistake only
+func TestCheck_MistakeWithoutConflict(t *testing.T) {
+	solution := examplePuzzle
+	solver.Solve(&solution)
+
+	// (0, 0) is 3 in the solution; 2 and 7 are also legal there right now
+	player := examplePuzzle
+	player[0][0] = 2
+
+	report, err := checker.Check(&examplePuzzle, &player)
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+
+	if len(report.Conflicts) != 0 {
+		t.Errorf("Check() conflicts = %v, expected none", report.Conflicts)
+	}
+	expected := checker.Mistake{Row: 0, Col: 0, Entered: 2, Expected: solution[0][0]}
+	if len(report.Mistakes) != 1 || report.Mistakes[0] != expected {
+		t.Errorf("Check() mistakes = %v, expected [%v]", report.Mistakes, expected)
+	}
+}
+
+// TestCheck_Conflict verifies that a clashing entry is reported both as a
+// conflict and as a mistake
+func TestCheck_Conflict(t *testing.T) {
+	// 9 is already given at (0, 1)
+	player := examplePuzzle
+	player[0][0] = 9
+
+	report, err := checker.Check(&examplePuzzle, &player)
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+
+	if len(report.Conflicts) == 0 {
+		t.Errorf("Check() reported no conflicts, expected the duplicate 9")
+	}
+	if len(report.Mistakes) != 1 || report.Mistakes[0].Row != 0 || report.Mistakes[0].Col != 0 {
+		t.Errorf("Check() mistakes = %v, expected one at (0, 0)", report.Mistakes)
+	}
+}
+
+// TestCheck_ChangedGivens verifies that cleared or overwritten givens are
+// reported separately from mistakes
+func TestCheck_ChangedGivens(t *testing.T) {
+	player := examplePuzzle
+	player[0][1] = 0 // Given 9 cleared
+	player[0][2] = 7 // Given 6 overwritten
+
+	report, err := checker.Check(&examplePuzzle, &player)
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+
+	if len(report.ChangedGivens) != 2 || len(report.Mistakes) != 0 {
+		t.Errorf("Check() changed givens = %v, mistakes = %v, expected 2 and none",
+			report.ChangedGivens, report.Mistakes)
+	}
+}
+
+// TestCheck_NoUniqueSolution verifies that ambiguous puzzles are rejected
+func TestCheck_NoUniqueSolution(t *testing.T) {
+	empty := utils.NewBoard()
+	if _, err := checker.Check(&empty, &empty); !errors.Is(err, checker.ErrNoUniqueSolution) {
+		t.Errorf("Check() error = %v, expected ErrNoUniqueSolution", err)
+	}
+}
@@ -0,0 +1,331 @@
+package test
+
+import (
+	"errors"
+	"math/rand"
+	"sudoku/generator"
+	"sudoku/grader"
+	"sudoku/solver"
+	"sudoku/utils"
+	"sudoku/validator"
+	"testing"
+)
+
+// TestFullGrid verifies that full grids are complete and follow every rule
+func TestFullGrid(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		grid := generator.FullGrid(rand.New(rand.NewSource(seed)))
+		if row, col := utils.FindEmptyCell(&grid); row != -1 {
+			t.Errorf("FullGrid(seed %d) left (%d, %d) empty", seed, row, col)
+		}
+		if conflicts := validator.ValidateBoard(&grid); len(conflicts) > 0 {
+			t.Errorf("FullGrid(seed %d) breaks a rule: %v", seed, conflicts[0])
+		}
+	}
+}
+
+// TestGenerate_UniqueAndMinimal verifies that generated puzzles have exactly
+// one solution, and that no clue can be removed without losing that
+func TestGenerate_UniqueAndMinimal(t *testing.T) {
+	for seed := int64(1); seed <= 3; seed++ {
+		puzzle := generator.Generate(rand.New(rand.NewSource(seed)))
+		if count := solver.CountSolutions(&puzzle, 2); count != 1 {
+			t.Fatalf("Generate(seed %d) has %d solutions, expected 1", seed, count)
+		}
+
+		for row := 0; row < 9; row++ {
+			for col := 0; col < 9; col++ {
+				if puzzle[row][col] == 0 {
+					continue
+				}
+				reduced := puzzle
+				reduced[row][col] = 0
+				if generator.HasUniqueSolution(&reduced) {
+					t.Errorf("Generate(seed %d) clue at (%d, %d) is redundant", seed, row, col)
+				}
+			}
+		}
+	}
+}
+
+// TestGenerate_Reproducible verifies that the same seed gives the same puzzle
+// and a different seed a different one
+func TestGenerate_Reproducible(t *testing.T) {
+	first := generator.Generate(rand.New(rand.NewSource(42)))
+	second := generator.Generate(rand.New(rand.NewSource(42)))
+	other := generator.Generate(rand.New(rand.NewSource(43)))
+
+	if first != second {
+		t.Errorf("Generate() with the same seed produced different puzzles")
+	}
+	if first == other {
+		t.Errorf("Generate() with different seeds produced the same puzzle")
+	}
+}
+
+// TestHasUniqueSolution verifies the uniqueness check on known boards
+func TestHasUniqueSolution(t *testing.T) {
+	empty := utils.NewBoard()
+	tests := []struct {
+		name     string
+		board    utils.Board
+		expected bool
+	}{
+		{"unique", examplePuzzle, true},
+		{"many solutions", empty, false},
+		{"no solution", unsolvablePuzzle, false},
+	}
+
+	for _, test := range tests {
+		if result := generator.HasUniqueSolution(&test.board); result != test.expected {
+			t.Errorf("HasUniqueSolution(%s) = %v, expected %v", test.name, result, test.expected)
+		}
+	}
+}
+
+// TestGenerateWith_Targets verifies that accepted puzzles meet the level,
+// givens and symmetry constraints and are still unique
+func TestGenerateWith_Targets(t *testing.T) {
+	opts := generator.DefaultOptions()
+	opts.MinLevel = grader.Medium
+	opts.MaxLevel = grader.Expert
+	opts.MinGivens = 26
+	opts.MaxGivens = 32
+	opts.Symmetry = generator.Rotational180
+
+	puzzle, err := generator.GenerateWith(rand.New(rand.NewSource(3)), opts)
+	if err != nil {
+		t.Fatalf("GenerateWith() unexpected error: %v", err)
+	}
+
+	board := puzzle.Board
+	if puzzle.Rating.Level < opts.MinLevel || puzzle.Rating.Level > opts.MaxLevel {
+		t.Errorf("GenerateWith() level = %v, expected Medium to Expert", puzzle.Rating.Level)
+	}
+	if givens := generator.CountGivens(&board); givens != puzzle.Givens || givens < 26 || givens > 32 {
+		t.Errorf("GenerateWith() has %d givens (reported %d), expected 26 to 32", givens, puzzle.Givens)
+	}
+	if !generator.HasUniqueSolution(&board) {
+		t.Errorf("GenerateWith() puzzle is not unique")
+	}
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if (board[row][col] == 0) != (board[8-row][8-col] == 0) {
+				t.Errorf("GenerateWith() breaks 180 symmetry at (%d, %d)", row, col)
+			}
+		}
+	}
+}
+
+// TestGenerateWith_TargetNotMet verifies that an unreachable target is
+// reported once the attempts are used up
+func TestGenerateWith_TargetNotMet(t *testing.T) {
+	opts := generator.DefaultOptions()
+	opts.MaxGivens = 17
+	opts.MaxAttempts = 2
+
+	_, err := generator.GenerateWith(rand.New(rand.NewSource(1)), opts)
+	if !errors.Is(err, generator.ErrTargetNotMet) {
+		t.Errorf("GenerateWith() error = %v, expected ErrTargetNotMet", err)
+	}
+}
+
+// TestGenerateWith_InvalidOptions verifies that contradictory options are
+// rejected before any attempt is made
+func TestGenerateWith_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*generator.Options)
+	}{
+		{"levels reversed", func(o *generator.Options) { o.MinLevel, o.MaxLevel = grader.Hard, grader.Easy }},
+		{"givens reversed", func(o *generator.Options) { o.MinGivens, o.MaxGivens = 30, 25 }},
+		{"too few givens", func(o *generator.Options) { o.MinGivens, o.MaxGivens = 10, 16 }},
+		{"no attempts", func(o *generator.Options) { o.MaxAttempts = 0 }},
+		{"jigsaw", func(o *generator.Options) { o.Variant = mustJigsaw(t) }},
+	}
+
+	for _, test := range tests {
+		opts := generator.DefaultOptions()
+		test.modify(&opts)
+		if _, err := generator.GenerateWith(rand.New(rand.NewSource(1)), opts); !errors.Is(err, generator.ErrInvalidOptions) {
+			t.Errorf("GenerateWith(%s) error = %v, expected ErrInvalidOptions", test.name, err)
+		}
+	}
+}
+
+// TestGenerateWith_Killer verifies that cage sums are followed like any other
+// rule: the cages of killerCages leave room for a single grid, which needs
+// no givens at all
+func TestGenerateWith_Killer(t *testing.T) {
+	opts := generator.DefaultOptions()
+	opts.Variant = mustKiller(t)
+	opts.MinGivens = 0
+
+	puzzle, err := generator.GenerateWith(rand.New(rand.NewSource(1)), opts)
+	if err != nil {
+		t.Fatalf("GenerateWith(killer) error = %v", err)
+	}
+	if puzzle.Givens != 0 {
+		t.Errorf("Givens = %d, expected 0", puzzle.Givens)
+	}
+	if puzzle.Rating.Level != grader.Diabolical {
+		t.Errorf("Rating = %v, expected Diabolical", puzzle.Rating)
+	}
+}
+
+// TestGenerateWith_VariantFewGivens verifies that the 17-given floor of
+// classic sudoku does not hold back variants, whose extra rules can pin down
+// a solution with fewer clues
+func TestGenerateWith_VariantFewGivens(t *testing.T) {
+	opts := generator.DefaultOptions()
+	opts.Variant = validator.Combine(validator.AntiKnight(), validator.AntiKing())
+	opts.MinGivens, opts.MaxGivens = 5, 16
+
+	puzzle, err := generator.GenerateWith(rand.New(rand.NewSource(3)), opts)
+	if err != nil {
+		t.Fatalf("GenerateWith(%v) error = %v", opts.Variant, err)
+	}
+	if puzzle.Givens > 16 {
+		t.Errorf("Givens = %d, expected at most 16", puzzle.Givens)
+	}
+	if count := (&solver.Backtracker{Variant: opts.Variant}).CountSolutions(&puzzle.Board, 2); count != 1 {
+		t.Errorf("CountSolutions() = %d, expected 1", count)
+	}
+}
+
+// TestGenerateWith_Unsatisfiable verifies that a variant whose rules leave
+// no solution is reported as invalid, not as an ambiguous puzzle
+func TestGenerateWith_Unsatisfiable(t *testing.T) {
+	// Nine different digits cannot all be even
+	opts := generator.DefaultOptions()
+	opts.Variant = validator.Combine(validator.Diagonals(), validator.NewConstraintVariant("even-diagonal", evenDiagonal{}))
+
+	_, err := generator.GenerateWith(rand.New(rand.NewSource(1)), opts)
+	if !errors.Is(err, generator.ErrInvalidOptions) {
+		t.Errorf("GenerateWith(%v) error = %v, expected ErrInvalidOptions", opts.Variant, err)
+	}
+}
+
+// TestGenerateWith_Windoku verifies that variant puzzles are unique under
+// the variant's rules, and rely on them
+func TestGenerateWith_Windoku(t *testing.T) {
+	opts := generator.DefaultOptions()
+	opts.Variant = validator.Windows()
+	puzzle, err := generator.GenerateWith(rand.New(rand.NewSource(5)), opts)
+	if err != nil {
+		t.Fatalf("GenerateWith(windoku) error = %v", err)
+	}
+
+	solutions := (&solver.Backtracker{Variant: opts.Variant}).FindSolutions(&puzzle.Board, 2)
+	if len(solutions) != 1 {
+		t.Fatalf("windoku FindSolutions() = %d solutions, expected 1", len(solutions))
+	}
+	if conflicts := validator.ValidateVariant(&solutions[0], opts.Variant); len(conflicts) > 0 {
+		t.Errorf("solution breaks a window: %v", conflicts[0])
+	}
+	if generator.HasUniqueSolution(&puzzle.Board) {
+		t.Errorf("HasUniqueSolution() = true, expected the puzzle to need its windows")
+	}
+}
+
+// TestSymmetry_Orbit verifies the orbit sizes of a corner, the middle of
+// the top edge and the centre under each symmetry
+func TestSymmetry_Orbit(t *testing.T) {
+	tests := []struct {
+		symmetry             generator.Symmetry
+		corner, edge, centre int
+	}{
+		{generator.NoSymmetry, 1, 1, 1},
+		{generator.Rotational180, 2, 2, 1},
+		{generator.Rotational90, 4, 4, 1},
+		{generator.Horizontal, 2, 2, 1},
+		{generator.Vertical, 2, 1, 1},
+		{generator.Diagonal, 1, 2, 1},
+		{generator.AntiDiagonal, 2, 2, 1},
+		{generator.Dihedral, 4, 4, 1},
+	}
+
+	for _, test := range tests {
+		corner := len(test.symmetry.Orbit(0, 0))
+		edge := len(test.symmetry.Orbit(0, 4))
+		centre := len(test.symmetry.Orbit(4, 4))
+		if corner != test.corner || edge != test.edge || centre != test.centre {
+			t.Errorf("%v orbit sizes = %d, %d, %d, expected %d, %d, %d", test.symmetry,
+				corner, edge, centre, test.corner, test.edge, test.centre)
+		}
+	}
+
+	// Away from the axes a full dihedral orbit has eight cells
+	if orbit := generator.Dihedral.Orbit(0, 1); len(orbit) != 8 {
+		t.Errorf("Dihedral.Orbit(0, 1) has %d cells, expected 8", len(orbit))
+	}
+}
+
+// TestGenerateWith_EverySymmetry verifies that each symmetry mode produces
+// unique puzzles whose clue pattern has that symmetry
+func TestGenerateWith_EverySymmetry(t *testing.T) {
+	for _, symmetry := range generator.AllSymmetries() {
+		opts := generator.DefaultOptions()
+		opts.Symmetry = symmetry
+
+		puzzle, err := generator.GenerateWith(rand.New(rand.NewSource(5)), opts)
+		if err != nil {
+			t.Errorf("GenerateWith(%v) unexpected error: %v", symmetry, err)
+			continue
+		}
+		if !symmetry.Holds(&puzzle.Board) {
+			t.Errorf("GenerateWith(%v) clue pattern breaks the symmetry", symmetry)
+		}
+		if !generator.HasUniqueSolution(&puzzle.Board) {
+			t.Errorf("GenerateWith(%v) puzzle is not unique", symmetry)
+		}
+	}
+}
+
+// TestSymmetriesOf verifies which symmetries known clue patterns report
+func TestSymmetriesOf(t *testing.T) {
+	// A single clue in the centre has every symmetry
+	centre := utils.NewBoard()
+	centre[4][4] = 5
+
+	// Clues in two opposite corners are symmetric by a half turn and by
+	// both diagonal mirrors, but not by a quarter turn
+	corners := utils.NewBoard()
+	corners[0][0], corners[8][8] = 1, 2
+
+	tests := []struct {
+		name     string
+		board    utils.Board
+		expected []generator.Symmetry
+	}{
+		{"centre", centre, generator.AllSymmetries()[1:]},
+		{"corners", corners, []generator.Symmetry{generator.Rotational180, generator.Diagonal, generator.AntiDiagonal}},
+		{"example", examplePuzzle, nil},
+	}
+
+	for _, test := range tests {
+		result := generator.SymmetriesOf(&test.board)
+		if len(result) != len(test.expected) {
+			t.Errorf("SymmetriesOf(%s) = %v, expected %v", test.name, result, test.expected)
+			continue
+		}
+		for i := range result {
+			if result[i] != test.expected[i] {
+				t.Errorf("SymmetriesOf(%s) = %v, expected %v", test.name, result, test.expected)
+				break
+			}
+		}
+	}
+}
+
+// TestParseSymmetry verifies that every symmetry name parses back to itself
+func TestParseSymmetry(t *testing.T) {
+	for _, symmetry := range generator.AllSymmetries() {
+		if parsed, err := generator.ParseSymmetry(symmetry.String()); err != nil || parsed != symmetry {
+			t.Errorf("ParseSymmetry(%q) = %v, %v, expected %v", symmetry.String(), parsed, err, symmetry)
+		}
+	}
+	if _, err := generator.ParseSymmetry("spiral"); err == nil {
+		t.Errorf("ParseSymmetry(%q) expected an error", "spiral")
+	}
+}
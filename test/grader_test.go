@@ -0,0 +1,134 @@
+package test
+
+import (
+	"errors"
+	"sudoku/grader"
+	"sudoku/logic"
+	"sudoku/utils"
+	"sudoku/validator"
+	"testing"
+)
+
+// TestGrade_Levels verifies that puzzles are rated by the hardest
+// technique they need
+func TestGrade_Levels(t *testing.T) {
+	testCases := []struct {
+		name    string
+		puzzle  utils.Board
+		level   grader.Level
+		hardest string
+		score   float64
+	}{
+		{"singles", examplePuzzle, grader.Easy, "Hidden Single", 1.5},
+		{"pointing", mustParse("7.1..6... ....9.1.. .6...3.4. .4.38.5.1 ..5....9. 2........ .....1... 5...4.9.. 6..8..72."),
+			grader.Medium, "Pointing Pair", 2.6},
+		{"x-wing", mustParse("23.9..... ........9 ...71.3.. ..13.64.. ..4.7..8. 5....4... ..6.8.7.. .....1... .1....653"),
+			grader.Hard, "X-Wing", 3.2},
+		{"xy-wing", mustParse("......... .7..163.. .2.7....4 .....7.8. 3.5..1... 86..53..2 .9.....2. ........9 6..48...."),
+			grader.Expert, "XY-Wing", 4.2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			board := tc.puzzle
+			rating, err := grader.Grade(&board)
+			if err != nil {
+				t.Fatalf("Grade() unexpected error: %v", err)
+			}
+
+			if rating.Level != tc.level {
+				t.Errorf("Grade() level = %v, expected %v", rating.Level, tc.level)
+			}
+			if rating.Score != tc.score {
+				t.Errorf("Grade() score = %v, expected %v", rating.Score, tc.score)
+			}
+			if rating.HardestTechnique() != tc.hardest {
+				t.Errorf("HardestTechnique() = %q, expected %q", rating.HardestTechnique(), tc.hardest)
+			}
+			if board != tc.puzzle {
+				t.Errorf("Grade() modified the input board")
+			}
+		})
+	}
+}
+
+// TestGrade_Diabolical verifies that puzzles logic cannot finish are rated
+// Diabolical with a score above every technique
+func TestGrade_Diabolical(t *testing.T) {
+	board := alEscargot
+	rating, err := grader.Grade(&board)
+	if err != nil {
+		t.Fatalf("Grade() unexpected error: %v", err)
+	}
+
+	if rating.Level != grader.Diabolical || !rating.NeedsGuess {
+		t.Errorf("Grade() = %v (needs guess %v), expected Diabolical", rating.Level, rating.NeedsGuess)
+	}
+	if rating.Score <= grader.Score(logic.HiddenQuad) || rating.Score > 10 {
+		t.Errorf("Grade() score = %v, expected between %v and 10", rating.Score, grader.Score(logic.HiddenQuad))
+	}
+}
+
+// TestGrade_Errors verifies that ambiguous and inconsistent puzzles are not rated
+func TestGrade_Errors(t *testing.T) {
+	empty := utils.NewBoard()
+	if _, err := grader.Grade(&empty); !errors.Is(err, grader.ErrNotUnique) {
+		t.Errorf("Grade() on empty board error = %v, expected ErrNotUnique", err)
+	}
+
+	invalid := utils.NewBoard()
+	invalid[0][0], invalid[8][0] = 2, 2
+	if _, err := grader.Grade(&invalid); !errors.Is(err, logic.ErrInvalidBoard) {
+		t.Errorf("Grade() on invalid board error = %v, expected ErrInvalidBoard", err)
+	}
+}
+
+// TestGradeVariant verifies that uniqueness is judged under the variant's
+// rules, that puzzles resting on sums and clues rate Diabolical, and that
+// jigsaw puzzles are refused
+func TestGradeVariant(t *testing.T) {
+	if _, err := grader.Grade(&xPuzzle); !errors.Is(err, grader.ErrNotUnique) {
+		t.Errorf("Grade(x puzzle) error = %v, expected ErrNotUnique", err)
+	}
+	rating, err := grader.GradeVariant(&xPuzzle, validator.Diagonals())
+	if err != nil || rating.Level != grader.Diabolical {
+		t.Errorf("GradeVariant(x puzzle) = %v, %v, expected Diabolical (needs the diagonals)", rating, err)
+	}
+
+	empty := utils.NewBoard()
+	tests := []struct {
+		variant *validator.Variant
+		puzzle  utils.Board
+	}{
+		{mustKiller(t), empty},
+		{mustLineClues(t), lineCluesPuzzle},
+	}
+	for _, test := range tests {
+		rating, err := grader.GradeVariant(&test.puzzle, test.variant)
+		if err != nil || rating.Level != grader.Diabolical {
+			t.Errorf("GradeVariant(%v) = %v, %v, expected Diabolical", test.variant, rating, err)
+		}
+	}
+
+	if _, err := grader.GradeVariant(&jigsawPuzzle, mustJigsaw(t)); !errors.Is(err, grader.ErrUnsupported) {
+		t.Errorf("GradeVariant(jigsaw) error = %v, expected ErrUnsupported", err)
+	}
+}
+
+// TestRating_String verifies the "Level (score)" format
+func TestRating_String(t *testing.T) {
+	rating := grader.Rating{Level: grader.Hard, Score: 3.2}
+	if rating.String() != "Hard (3.2)" {
+		t.Errorf("Rating.String() = %q, expected %q", rating.String(), "Hard (3.2)")
+	}
+}
+
+// TestParseLevel verifies that level names are matched in any case
+func TestParseLevel(t *testing.T) {
+	if level, err := grader.ParseLevel("expert"); err != nil || level != grader.Expert {
+		t.Errorf("ParseLevel(%q) = %v, %v, expected Expert", "expert", level, err)
+	}
+	if _, err := grader.ParseLevel("impossible"); err == nil {
+		t.Errorf("ParseLevel(%q) expected an error", "impossible")
+	}
+}
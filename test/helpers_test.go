@@ -4,6 +4,10 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"strings"
+	"sudoku/parser"
+	"sudoku/utils"
+	"sudoku/validator"
 )
 
 // captureOutput captures what gets printed to stdout
@@ -32,3 +36,32 @@ func captureOutput(f func()) string {
 	// Return the captured output as a string
 	return buf.String()
 }
+
+// naiveSolve is the original backtracker that rescans the row, column and box
+// through validator.IsValid for every trial; benchmarks compare against it
+func naiveSolve(board *utils.Board) bool {
+	row, col := utils.FindEmptyCell(board)
+	if row == -1 {
+		return true
+	}
+	for num := 1; num <= 9; num++ {
+		if validator.IsValid(board, row, col, num) {
+			board[row][col] = num
+			if naiveSolve(board) {
+				return true
+			}
+			board[row][col] = 0
+		}
+	}
+	return false
+}
+
+// mustParse builds a board from nine space-separated rows in the CLI format
+// Panics on malformed input, since test puzzles are fixed
+func mustParse(rows string) utils.Board {
+	board, err := parser.ParseArgs(strings.Fields(rows))
+	if err != nil {
+		panic(err)
+	}
+	return board
+}
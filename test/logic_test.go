@@ -0,0 +1,337 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"sudoku/candidates"
+	"sudoku/logic"
+	"sudoku/solver"
+	"sudoku/utils"
+	"sudoku/validator"
+	"testing"
+)
+
+// checkSteps verifies every deduction against the puzzle's unique solution:
+// placements must match it and eliminations must never remove it
+func checkSteps(t *testing.T, puzzle utils.Board, result logic.Result) {
+	t.Helper()
+
+	solution := puzzle
+	if !solver.Solve(&solution) {
+		t.Fatalf("puzzle has no solution")
+	}
+
+	for _, step := range result.Steps {
+		for _, p := range step.Placements {
+			if solution[p.Row][p.Col] != p.Digit {
+				t.Errorf("%v placed %v, solution has %d", step, p, solution[p.Row][p.Col])
+			}
+		}
+		for _, e := range step.Eliminations {
+			if solution[e.Row][e.Col] == e.Digit {
+				t.Errorf("%v eliminated %v, which is the solution", step, e)
+			}
+		}
+		if len(step.Placements)+len(step.Eliminations) == 0 {
+			t.Errorf("%v made no progress", step)
+		}
+	}
+
+	if result.Solved && result.Board != solution {
+		t.Errorf("Solve() board differs from the unique solution")
+	}
+}
+
+// TestLogicSolve_SinglesOnly verifies that an easy puzzle is solved with
+// singles only, recording one step per empty cell
+func TestLogicSolve_SinglesOnly(t *testing.T) {
+	board := examplePuzzle
+	result, err := logic.Solve(&board)
+	if err != nil {
+		t.Fatalf("Solve() unexpected error: %v", err)
+	}
+
+	if !result.Solved {
+		t.Fatalf("Solve() Solved = false on easy puzzle, expected true")
+	}
+	if len(result.Steps) != 41 {
+		t.Errorf("Solve() made %d steps, expected 41 (one per empty cell)", len(result.Steps))
+	}
+	if hardest, _ := result.Hardest(); hardest != logic.HiddenSingle {
+		t.Errorf("Hardest() = %v, expected Hidden Single", hardest)
+	}
+	if board != examplePuzzle {
+		t.Errorf("Solve() modified the input board")
+	}
+	checkSteps(t, board, result)
+}
+
+// TestLogicSolve_Techniques verifies that puzzles needing a specific
+// technique are solved with it, and that every step is sound
+func TestLogicSolve_Techniques(t *testing.T) {
+	testCases := []struct {
+		technique logic.Technique
+		puzzle    string
+	}{
+		{logic.PointingPair, "7.1..6... ....9.1.. .6...3.4. .4.38.5.1 ..5....9. 2........ .....1... 5...4.9.. 6..8..72."},
+		{logic.BoxLineReduction, ".......2. ....35... 68...4.5. 7...1...2 ..58..4.. ...2.35.. 2.1.8.9.3 .......8. ..4.5..6."},
+		{logic.NakedPair, ".4..5...1 9..8..... ..6..243. .64....7. 2..7..... .......8. ......5.. ....9.12. .7..48..."},
+		{logic.XWing, "23.9..... ........9 ...71.3.. ..13.64.. ..4.7..8. 5....4... ..6.8.7.. .....1... .1....653"},
+		{logic.HiddenPair, "....3.... 13642...8 .2...8.5. ..2....87 .9....... ...5..63. ....675.1 .....3... .6...12.."},
+		{logic.NakedTriple, "..234.17. 6..17...5 ..42....9 1........ ...597..2 ..7...... .5...2... ...7.8.3. .......9."},
+		{logic.XYWing, "......... .7..163.. .2.7....4 .....7.8. 3.5..1... 86..53..2 .9.....2. ........9 6..48...."},
+		{logic.XYZWing, "6.8..2... ...6..... 3..1....7 ..1...65. ......... .4..59... ..3....7. .1.28..9. ...79..23"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.technique.String(), func(t *testing.T) {
+			board := mustParse(tc.puzzle)
+			result, err := logic.Solve(&board)
+			if err != nil {
+				t.Fatalf("Solve() unexpected error: %v", err)
+			}
+
+			if !result.Solved {
+				t.Errorf("Solve() Solved = false, expected true")
+			}
+			if hardest, _ := result.Hardest(); hardest != tc.technique {
+				t.Errorf("Hardest() = %v, expected %v (used %v)",
+					hardest, tc.technique, result.Techniques())
+			}
+			checkSteps(t, board, result)
+		})
+	}
+}
+
+// TestLogicSolve_AdvancedEliminations verifies sound Swordfish, Hidden Triple
+// and Jellyfish steps on puzzles logic alone cannot finish
+func TestLogicSolve_AdvancedEliminations(t *testing.T) {
+	testCases := []struct {
+		technique logic.Technique
+		puzzle    string
+	}{
+		{logic.Swordfish, "...1....7 2.3...... .9..3.... ..56....3 ......... .7.5.18.. .......6. .8..1.29. .2..851.."},
+		{logic.HiddenTriple, "..32..... .45...... 67.1.3.5. .1.....7. ..86....3 ..7..5.2. .....96.. ...7....1 ...4...82"},
+		{logic.Jellyfish, "...3.14.. .5..7.... ...6...5. 9......81 ...59...4 4....653. 2.1...69. .3.9..... 6.4..5..."},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.technique.String(), func(t *testing.T) {
+			board := mustParse(tc.puzzle)
+			result, err := logic.Solve(&board)
+			if err != nil {
+				t.Fatalf("Solve() unexpected error: %v", err)
+			}
+
+			used := false
+			for _, technique := range result.Techniques() {
+				used = used || technique == tc.technique
+			}
+			if !used {
+				t.Errorf("Techniques() = %v, expected it to include %v", result.Techniques(), tc.technique)
+			}
+			checkSteps(t, board, result)
+		})
+	}
+}
+
+// TestLogicSolve_NeedsGuessing verifies that a puzzle beyond the supported
+// techniques is reported as unsolved, with every step still sound
+func TestLogicSolve_NeedsGuessing(t *testing.T) {
+	board := alEscargot
+	result, err := logic.Solve(&board)
+	if err != nil {
+		t.Fatalf("Solve() unexpected error: %v", err)
+	}
+
+	if result.Solved {
+		t.Errorf("Solve() Solved = true on Al Escargot, expected false")
+	}
+	checkSteps(t, board, result)
+}
+
+// TestLogicSolve_InvalidBoard verifies that inconsistent givens are rejected
+func TestLogicSolve_InvalidBoard(t *testing.T) {
+	board := utils.NewBoard()
+	board[0][0], board[0][8] = 4, 4
+
+	if _, err := logic.Solve(&board); !errors.Is(err, logic.ErrInvalidBoard) {
+		t.Errorf("Solve() error = %v, expected ErrInvalidBoard", err)
+	}
+}
+
+// TestStep_String verifies that steps read as "Technique: explanation"
+func TestStep_String(t *testing.T) {
+	board := examplePuzzle
+	result, _ := logic.Solve(&board)
+
+	first := result.Steps[0].String()
+	if !strings.HasPrefix(first, "Hidden Single: ") {
+		t.Errorf("Step.String() = %q, expected prefix %q", first, "Hidden Single: ")
+	}
+}
+
+// TestAllTechniques verifies that techniques are listed easiest first
+// and all have display names
+func TestAllTechniques(t *testing.T) {
+	techniques := logic.AllTechniques()
+	if techniques[0] != logic.HiddenSingle {
+		t.Errorf("AllTechniques()[0] = %v, expected Hidden Single", techniques[0])
+	}
+	for i, technique := range techniques {
+		if i > 0 && technique <= techniques[i-1] {
+			t.Errorf("AllTechniques() out of order at %d", i)
+		}
+		if strings.HasPrefix(technique.String(), "Technique(") {
+			t.Errorf("Technique %d has no display name", int(technique))
+		}
+	}
+}
+
+// TestHint_NextStep verifies that a hint is the single easiest step, agrees
+// with the solution and leaves the board unchanged
+func TestHint_NextStep(t *testing.T) {
+	board := examplePuzzle
+	step, err := logic.Hint(&board)
+	if err != nil {
+		t.Fatalf("Hint() unexpected error: %v", err)
+	}
+
+	if step.Technique != logic.HiddenSingle || len(step.Placements) != 1 {
+		t.Errorf("Hint() = %v, expected one Hidden Single placement", step)
+	}
+	if board != examplePuzzle {
+		t.Errorf("Hint() modified the input board")
+	}
+	checkSteps(t, board, logic.Result{Steps: []logic.Step{step}})
+
+	// The hint is the first step a full logic solve would make
+	result, _ := logic.Solve(&board)
+	if step.String() != result.Steps[0].String() {
+		t.Errorf("Hint() = %v, expected %v", step, result.Steps[0])
+	}
+}
+
+// TestHint_Eliminations verifies that a hint may remove candidates
+// instead of solving a cell, and names the cells that justify it
+func TestHint_Eliminations(t *testing.T) {
+	// Fill in every single first, so only a Pointing Pair applies
+	board := mustParse("7.1..6... ....9.1.. .6...3.4. .4.38.5.1 ..5....9. 2........ .....1... 5...4.9.. 6..8..72.")
+	for {
+		step, err := logic.Hint(&board)
+		if err != nil {
+			t.Fatalf("Hint() unexpected error: %v", err)
+		}
+		if len(step.Placements) == 0 {
+			if step.Technique != logic.PointingPair || len(step.Eliminations) == 0 || len(step.Cells) == 0 {
+				t.Errorf("Hint() = %v, expected a Pointing Pair with eliminations and cells", step)
+			}
+			checkSteps(t, board, logic.Result{Steps: []logic.Step{step}})
+			return
+		}
+		for _, p := range step.Placements {
+			board[p.Row][p.Col] = p.Digit
+		}
+	}
+}
+
+// TestHint_SingleCells verifies that singles are justified by the filled
+// cells that rule out the alternatives, never by the cell being placed
+// A hidden single names the cells holding its digit, or the peers holding
+// every other digit when it fills the last empty cell of its unit
+func TestHint_SingleCells(t *testing.T) {
+	board := mustParse("7.1..6... ....9.1.. .6...3.4. .4.38.5.1 ..5....9. 2........ .....1... 5...4.9.. 6..8..72.")
+	naked, hidden := 0, 0
+	for {
+		step, err := logic.Hint(&board)
+		if err != nil || len(step.Placements) == 0 {
+			break
+		}
+		p := step.Placements[0]
+		target := validator.Cell{Row: p.Row, Col: p.Col}
+		if len(step.Cells) == 0 {
+			t.Errorf("%v has no justifying cells", step)
+		}
+		for _, cell := range step.Cells {
+			value := board[cell.Row][cell.Col]
+			switch {
+			case cell == target || value == 0:
+				t.Errorf("%v is justified by %v, expected a filled cell other than the target", step, cell)
+			case step.Technique == logic.NakedSingle && (value == p.Digit || !candidates.Sees(cell, target)):
+				t.Errorf("%v is justified by %v holding %d, expected a peer holding another digit", step, cell, value)
+			case value != p.Digit && !candidates.Sees(cell, target):
+				t.Errorf("%v is justified by %v holding %d, expected %d or a peer", step, cell, value, p.Digit)
+			}
+		}
+		if step.Technique == logic.NakedSingle {
+			naked++
+		} else {
+			hidden++
+		}
+		board[p.Row][p.Col] = p.Digit
+	}
+	if naked == 0 || hidden == 0 {
+		t.Errorf("Hint() gave %d naked and %d hidden singles, expected both kinds", naked, hidden)
+	}
+}
+
+// TestHint_Errors verifies the errors for solved, stuck and invalid boards
+func TestHint_Errors(t *testing.T) {
+	solved := examplePuzzle
+	solver.Solve(&solved)
+	empty := utils.NewBoard()
+	invalid := utils.NewBoard()
+	invalid[0][0], invalid[1][1] = 3, 3
+
+	tests := []struct {
+		name     string
+		board    utils.Board
+		expected error
+	}{
+		{"solved", solved, logic.ErrSolved},
+		{"empty", empty, logic.ErrNoHint},
+		{"invalid", invalid, logic.ErrInvalidBoard},
+	}
+
+	for _, test := range tests {
+		if _, err := logic.Hint(&test.board); !errors.Is(err, test.expected) {
+			t.Errorf("Hint(%s) error = %v, expected %v", test.name, err, test.expected)
+		}
+	}
+}
+
+// TestHintFrom_PencilMarks verifies that eliminations already made in the
+// player's pencil marks are not suggested again
+func TestHintFrom_PencilMarks(t *testing.T) {
+	// Fill in hints until the first one that only removes candidates
+	board := mustParse("7.1..6... ....9.1.. .6...3.4. .4.38.5.1 ..5....9. 2........ .....1... 5...4.9.. 6..8..72.")
+	marks := candidates.Compute(&board)
+	var first logic.Step
+	for {
+		step, err := logic.HintFrom(&board, &marks)
+		if err != nil {
+			t.Fatalf("HintFrom() unexpected error: %v", err)
+		}
+		if len(step.Placements) == 0 {
+			first = step
+			break
+		}
+		for _, p := range step.Placements {
+			board[p.Row][p.Col] = p.Digit
+			marks.Place(p.Row, p.Col, p.Digit)
+		}
+	}
+
+	// Rub out what the first hint suggested; the next hint must differ
+	for _, e := range first.Eliminations {
+		marks.Remove(e.Row, e.Col, e.Digit)
+	}
+	second, err := logic.HintFrom(&board, &marks)
+	if err != nil {
+		t.Fatalf("HintFrom() unexpected error: %v", err)
+	}
+	if second.String() == first.String() {
+		t.Errorf("HintFrom() repeated %v after its eliminations were made", first)
+	}
+	checkSteps(t, board, logic.Result{Steps: []logic.Step{first, second}})
+}
@@ -2,6 +2,8 @@ package test
 
 import (
 	"sudoku/parser"
+	"sudoku/utils"
+	"sudoku/validator"
 	"testing"
 )
 
@@ -398,3 +400,176 @@ func TestParseArgs_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+// TestParseGrid_Sizes tests parsing 6x6 and 16x16 grids
+func TestParseGrid_Sizes(t *testing.T) {
+	six := utils.Shape{BoxRows: 2, BoxCols: 3}
+	grid, err := parser.ParseGrid([]string{"1.....", ".2....", "..3...", "...4..", "....5.", ".....6"}, six, "")
+	if err != nil {
+		t.Fatalf("ParseGrid(6x6) unexpected error: %v", err)
+	}
+	if grid.Cells[0][0] != 1 || grid.Cells[5][5] != 6 || grid.Cells[0][1] != 0 {
+		t.Errorf("ParseGrid(6x6) cells = %v", grid.Cells)
+	}
+
+	hex := utils.Shape{BoxRows: 4, BoxCols: 4}
+	rows := make([]string, 16)
+	for i := range rows {
+		rows[i] = "................"
+	}
+	rows[3] = "G..............A"
+	grid, err = parser.ParseGrid(rows, hex, "")
+	if err != nil {
+		t.Fatalf("ParseGrid(16x16) unexpected error: %v", err)
+	}
+	if grid.Cells[3][0] != 16 || grid.Cells[3][15] != 10 {
+		t.Errorf("ParseGrid(16x16) row 3 = %v, expected 16 and 10 at the ends", grid.Cells[3])
+	}
+}
+
+// TestParseGrid_Errors tests the errors for malformed grids and alphabets
+func TestParseGrid_Errors(t *testing.T) {
+	four := utils.Shape{BoxRows: 2, BoxCols: 2}
+	tests := []struct {
+		name     string
+		rows     []string
+		alphabet string
+		expected string
+	}{
+		{"too few rows", []string{"....", "....", "...."}, "", "Error: Invalid number of arguments"},
+		{"short row", []string{"....", "...", "....", "...."}, "", "Error: Invalid row length"},
+		{"digit too big", []string{"5...", "....", "....", "...."}, "", "Error: Invalid character"},
+		{"outside alphabet", []string{"1...", "....", "....", "...."}, "wxyz", "Error: Invalid character"},
+		{"alphabet too short", []string{"....", "....", "....", "...."}, "abc", "Error: Invalid alphabet"},
+		{"repeated symbol", []string{"....", "....", "....", "...."}, "abca", "Error: Invalid alphabet"},
+	}
+
+	for _, test := range tests {
+		_, err := parser.ParseGrid(test.rows, four, test.alphabet)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("ParseGrid(%s) error = %v, expected %q", test.name, err, test.expected)
+		}
+	}
+}
+
+// TestParseRegions verifies region letters are numbered by first appearance
+// and malformed maps are rejected
+func TestParseRegions(t *testing.T) {
+	boxes, err := parser.ParseRegions(jigsawRows)
+	if err != nil {
+		t.Fatalf("ParseRegions() error = %v", err)
+	}
+	if boxes[0][0] != 0 || boxes[1][7] != 3 || boxes[2][1] != 4 || boxes[8][8] != 6 {
+		t.Errorf("ParseRegions() = %v, expected regions in order of first letter", boxes)
+	}
+
+	tests := []struct {
+		name     string
+		rows     []string
+		expected string
+	}{
+		{"too few rows", jigsawRows[:8], "Error: Invalid number of region rows"},
+		{"short row", append([]string{"AAABBBCC"}, jigsawRows[1:]...), "Error: Invalid region row length"},
+		{"ten regions", append([]string{"AAABBBCCJ"}, jigsawRows[1:]...), "Error: Too many regions"},
+	}
+	for _, test := range tests {
+		_, err := parser.ParseRegions(test.rows)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("ParseRegions(%s) error = %v, expected %q", test.name, err, test.expected)
+		}
+	}
+}
+
+// TestParseCages verifies the cage format and its errors
+func TestParseCages(t *testing.T) {
+	cages, err := parser.ParseCages("# two cages\n3: r1c1 R1C2\n\n 17 : r9c8 r9c9\n")
+	if err != nil {
+		t.Fatalf("ParseCages() error = %v", err)
+	}
+	if len(cages) != 2 || cages[0].Sum != 3 || cages[1].Sum != 17 {
+		t.Fatalf("ParseCages() = %v, expected sums 3 and 17", cages)
+	}
+	if cages[0].Cells[1] != (validator.Cell{Row: 0, Col: 1}) || cages[1].Cells[1] != (validator.Cell{Row: 8, Col: 8}) {
+		t.Errorf("ParseCages() cells = %v, expected 0-based positions", cages)
+	}
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"3 r1c1 r1c2", "Error: Invalid cage on line 1: expected \"sum: cells\""},
+		{"# sum\nx: r1c1", "Error: Invalid cage on line 2: expected \"sum: cells\""},
+		{"3: r1c1 r0c2", "Error: Invalid cell \"r0c2\" on line 1"},
+		{"3: r1c1 r1", "Error: Invalid cell \"r1\" on line 1"},
+	}
+	for _, test := range tests {
+		_, err := parser.ParseCages(test.text)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("ParseCages(%q) error = %v, expected %q", test.text, err, test.expected)
+		}
+	}
+}
+
+// TestParseDots verifies the dot format, the negative line and errors
+func TestParseDots(t *testing.T) {
+	dots, negative, err := parser.ParseDots("# dots\nwhite r1c1 r1c2\n\nBlack r3c4 r4c4\nnegative\n")
+	if err != nil {
+		t.Fatalf("ParseDots() error = %v", err)
+	}
+	expected := []validator.Dot{
+		{A: validator.Cell{Row: 0, Col: 0}, B: validator.Cell{Row: 0, Col: 1}},
+		{Black: true, A: validator.Cell{Row: 2, Col: 3}, B: validator.Cell{Row: 3, Col: 3}},
+	}
+	if len(dots) != 2 || dots[0] != expected[0] || dots[1] != expected[1] || !negative {
+		t.Errorf("ParseDots() = %v, %v, expected %v, true", dots, negative, expected)
+	}
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"grey r1c1 r1c2", "Error: Invalid dot on line 1: expected \"white|black cell cell\""},
+		{"white r1c1", "Error: Invalid dot on line 1: expected \"white|black cell cell\""},
+		{"\nblack r1c1 x", "Error: Invalid cell \"x\" on line 2"},
+	}
+	for _, test := range tests {
+		_, _, err := parser.ParseDots(test.text)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("ParseDots(%q) error = %v, expected %q", test.text, err, test.expected)
+		}
+	}
+}
+
+// TestParseClues verifies reading thermos, arrows and sandwiches
+func TestParseClues(t *testing.T) {
+	clues, err := parser.ParseClues("# clues\nthermo r1c1 r1c2\n\nArrow r5c5 r4c4 r3c3\nsandwich row 1 15\nsandwich Column 3 0\n")
+	if err != nil {
+		t.Fatalf("ParseClues() error = %v", err)
+	}
+	if len(clues.Thermos) != 1 || len(clues.Thermos[0]) != 2 || clues.Thermos[0][1] != (validator.Cell{Row: 0, Col: 1}) {
+		t.Errorf("ParseClues() thermos = %v, expected [[(0, 0) (0, 1)]]", clues.Thermos)
+	}
+	if len(clues.Arrows) != 1 || clues.Arrows[0].Circle != (validator.Cell{Row: 4, Col: 4}) || len(clues.Arrows[0].Cells) != 2 {
+		t.Errorf("ParseClues() arrows = %v, expected circle (4, 4) with 2 cells", clues.Arrows)
+	}
+	expected := []validator.Sandwich{{Index: 0, Sum: 15}, {Column: true, Index: 2, Sum: 0}}
+	if len(clues.Sandwiches) != 2 || clues.Sandwiches[0] != expected[0] || clues.Sandwiches[1] != expected[1] {
+		t.Errorf("ParseClues() sandwiches = %v, expected %v", clues.Sandwiches, expected)
+	}
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"killer r1c1", "Error: Invalid clue on line 1: expected \"thermo|arrow cells\" or \"sandwich row|column number sum\""},
+		{"thermo", "Error: Invalid clue on line 1: expected \"thermo|arrow cells\" or \"sandwich row|column number sum\""},
+		{"sandwich box 1 5", "Error: Invalid sandwich on line 1: expected \"sandwich row|column number sum\""},
+		{"\narrow r1c1 r2c0", "Error: Invalid cell \"r2c0\" on line 2"},
+	}
+	for _, test := range tests {
+		_, err := parser.ParseClues(test.text)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("ParseClues(%q) error = %v, expected %q", test.text, err, test.expected)
+		}
+	}
+}
@@ -1,9 +1,16 @@
 package test
 
 import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"sudoku/parser"
 	"sudoku/solver"
 	"sudoku/utils"
+	"sudoku/validator"
 	"testing"
+	"time"
 )
 
 // TestSolve_EmptyBoard verifies that the solver can solve a completely empty board
@@ -170,3 +177,1162 @@ func TestSolve_VeryHardPuzzle(t *testing.T) {
 		}
 	}
 }
+
+// TestCountSolutions_UniquePuzzle verifies that a proper puzzle has exactly one solution
+func TestCountSolutions_UniquePuzzle(t *testing.T) {
+	board := utils.Board{
+		{0, 9, 6, 0, 4, 0, 0, 0, 1},
+		{1, 0, 0, 0, 6, 0, 0, 0, 4},
+		{5, 0, 4, 8, 1, 0, 3, 9, 0},
+		{0, 0, 7, 9, 5, 0, 0, 4, 3},
+		{0, 3, 0, 0, 8, 0, 0, 0, 0},
+		{4, 0, 5, 0, 2, 3, 0, 1, 8},
+		{0, 1, 0, 6, 3, 0, 0, 5, 9},
+		{0, 5, 9, 0, 7, 0, 8, 3, 0},
+		{0, 0, 3, 5, 9, 0, 0, 0, 7},
+	}
+	original := board
+
+	// Ask for more than one so a second solution would be found
+	if count := solver.CountSolutions(&board, 2); count != 1 {
+		t.Errorf("CountSolutions() = %d, expected 1", count)
+	}
+
+	// Counting must not modify the board
+	if board != original {
+		t.Errorf("CountSolutions() modified the board, expected unchanged")
+	}
+}
+
+// TestCountSolutions_EmptyBoard verifies that counting stops at the limit
+// on a board with a huge number of solutions
+func TestCountSolutions_EmptyBoard(t *testing.T) {
+	board := utils.NewBoard()
+
+	for _, limit := range []int{1, 2, 5} {
+		if count := solver.CountSolutions(&board, limit); count != limit {
+			t.Errorf("CountSolutions(limit=%d) = %d on empty board, expected %d",
+				limit, count, limit)
+		}
+	}
+}
+
+// TestCountSolutions_InvalidPuzzle verifies that an unsolvable puzzle has no solutions
+func TestCountSolutions_InvalidPuzzle(t *testing.T) {
+	board := utils.Board{
+		{5, 1, 6, 8, 4, 9, 7, 3, 2},
+		{3, 0, 7, 6, 0, 5, 0, 0, 0},
+		{8, 0, 9, 7, 0, 0, 0, 6, 5},
+		{1, 3, 5, 0, 6, 0, 9, 0, 7},
+		{4, 7, 2, 5, 9, 1, 0, 0, 6},
+		{9, 6, 8, 3, 7, 0, 0, 5, 0},
+		{2, 5, 3, 1, 8, 6, 0, 7, 4},
+		{6, 8, 4, 2, 0, 7, 5, 0, 0},
+		{7, 9, 1, 0, 5, 0, 6, 0, 8},
+	}
+
+	if count := solver.CountSolutions(&board, 2); count != 0 {
+		t.Errorf("CountSolutions() = %d on unsolvable puzzle, expected 0", count)
+	}
+}
+
+// TestFindSolutions_TwoSolutions verifies that both solutions of an ambiguous
+// puzzle are returned and that they differ only in the ambiguous cells
+func TestFindSolutions_TwoSolutions(t *testing.T) {
+	// Solved example with a "deadly pattern" of 4 cells removed:
+	// (6,5)=8, (6,6)=4, (7,5)=4, (7,6)=8 can be swapped freely
+	board := utils.Board{
+		{3, 9, 6, 2, 4, 5, 7, 8, 1},
+		{1, 7, 8, 3, 6, 9, 5, 2, 4},
+		{5, 2, 4, 8, 1, 7, 3, 9, 6},
+		{2, 8, 7, 9, 5, 1, 6, 4, 3},
+		{9, 3, 1, 4, 8, 6, 2, 7, 5},
+		{4, 6, 5, 7, 2, 3, 9, 1, 8},
+		{7, 1, 2, 6, 3, 0, 0, 5, 9},
+		{6, 5, 9, 1, 7, 0, 0, 3, 2},
+		{8, 4, 3, 5, 9, 2, 1, 6, 7},
+	}
+
+	// A generous limit still only finds the two solutions that exist
+	solutions := solver.FindSolutions(&board, 10)
+	if len(solutions) != 2 {
+		t.Fatalf("FindSolutions() returned %d solutions, expected 2", len(solutions))
+	}
+
+	first, second := solutions[0], solutions[1]
+	if first == second {
+		t.Fatalf("FindSolutions() returned the same solution twice")
+	}
+
+	// Only the four removed cells may differ between the solutions
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if board[row][col] != 0 && first[row][col] != second[row][col] {
+				t.Errorf("Solutions differ at given cell (%d, %d)", row, col)
+			}
+		}
+	}
+}
+
+// TestSolve_InconsistentGivens verifies that the solver refuses a board whose
+// givens already break a rule, even when no empty cells remain
+func TestSolve_InconsistentGivens(t *testing.T) {
+	// A completely filled board with every row shifted by one: rows are fine,
+	// but columns and boxes are full of duplicates
+	board := utils.NewBoard()
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			board[row][col] = (row+col)%9 + 1
+		}
+	}
+
+	if solver.Solve(&board) {
+		t.Errorf("Solve() = true on inconsistent filled board, expected false")
+	}
+	if count := solver.CountSolutions(&board, 2); count != 0 {
+		t.Errorf("CountSolutions() = %d on inconsistent board, expected 0", count)
+	}
+}
+
+// Puzzles shared by the benchmarks below
+var (
+	// Example puzzle from sample outputs
+	examplePuzzle = utils.Board{
+		{0, 9, 6, 0, 4, 0, 0, 0, 1},
+		{1, 0, 0, 0, 6, 0, 0, 0, 4},
+		{5, 0, 4, 8, 1, 0, 3, 9, 0},
+		{0, 0, 7, 9, 5, 0, 0, 4, 3},
+		{0, 3, 0, 0, 8, 0, 0, 0, 0},
+		{4, 0, 5, 0, 2, 3, 0, 1, 8},
+		{0, 1, 0, 6, 3, 0, 0, 5, 9},
+		{0, 5, 9, 0, 7, 0, 8, 3, 0},
+		{0, 0, 3, 5, 9, 0, 0, 0, 7},
+	}
+
+	// "Al Escargot" by Arto Inkala
+	alEscargot = utils.Board{
+		{1, 0, 0, 0, 0, 7, 0, 9, 0},
+		{0, 3, 0, 0, 2, 0, 0, 0, 8},
+		{0, 0, 9, 6, 0, 0, 5, 0, 0},
+		{0, 0, 5, 3, 0, 0, 9, 0, 0},
+		{0, 1, 0, 0, 8, 0, 0, 0, 2},
+		{6, 0, 0, 0, 0, 4, 0, 0, 0},
+		{3, 0, 0, 0, 0, 0, 0, 1, 0},
+		{0, 4, 0, 0, 0, 0, 0, 0, 7},
+		{0, 0, 7, 0, 0, 0, 3, 0, 0},
+	}
+
+	// Consistent givens with no solution, so the whole tree is searched
+	unsolvablePuzzle = utils.Board{
+		{5, 1, 6, 8, 4, 9, 7, 3, 2},
+		{3, 0, 7, 6, 0, 5, 0, 0, 0},
+		{8, 0, 9, 7, 0, 0, 0, 6, 5},
+		{1, 3, 5, 0, 6, 0, 9, 0, 7},
+		{4, 7, 2, 5, 9, 1, 0, 0, 6},
+		{9, 6, 8, 3, 7, 0, 0, 5, 0},
+		{2, 5, 3, 1, 8, 6, 0, 7, 4},
+		{6, 8, 4, 2, 0, 7, 5, 0, 0},
+		{7, 9, 1, 0, 5, 0, 6, 0, 8},
+	}
+)
+
+// benchmarkSolve runs solve on a fresh copy of the puzzle each iteration
+func benchmarkSolve(b *testing.B, puzzle utils.Board, solve func(*utils.Board) bool) {
+	for i := 0; i < b.N; i++ {
+		board := puzzle
+		solve(&board)
+	}
+}
+
+// BenchmarkSolve_ExamplePuzzle measures the tracker-based solver on an easy puzzle
+func BenchmarkSolve_ExamplePuzzle(b *testing.B) {
+	benchmarkSolve(b, examplePuzzle, solver.Solve)
+}
+
+// BenchmarkNaiveSolve_ExamplePuzzle measures the IsValid-scanning solver on an easy puzzle
+func BenchmarkNaiveSolve_ExamplePuzzle(b *testing.B) {
+	benchmarkSolve(b, examplePuzzle, naiveSolve)
+}
+
+// BenchmarkSolve_VeryHardPuzzle measures the tracker-based solver on Al Escargot
+func BenchmarkSolve_VeryHardPuzzle(b *testing.B) {
+	benchmarkSolve(b, alEscargot, solver.Solve)
+}
+
+// BenchmarkNaiveSolve_VeryHardPuzzle measures the IsValid-scanning solver on Al Escargot
+func BenchmarkNaiveSolve_VeryHardPuzzle(b *testing.B) {
+	benchmarkSolve(b, alEscargot, naiveSolve)
+}
+
+// BenchmarkSolve_InvalidPuzzle measures the tracker-based solver exhausting the search
+func BenchmarkSolve_InvalidPuzzle(b *testing.B) {
+	benchmarkSolve(b, unsolvablePuzzle, solver.Solve)
+}
+
+// BenchmarkNaiveSolve_InvalidPuzzle measures the IsValid-scanning solver exhausting the search
+func BenchmarkNaiveSolve_InvalidPuzzle(b *testing.B) {
+	benchmarkSolve(b, unsolvablePuzzle, naiveSolve)
+}
+
+// antiBruteForce is designed against naive backtrackers: the first row is
+// empty and its solution is 987654321, so reading-order search tries almost
+// every wrong digit first
+var antiBruteForce = utils.Board{
+	{0, 0, 0, 0, 0, 0, 0, 0, 0},
+	{0, 0, 0, 0, 0, 3, 0, 8, 5},
+	{0, 0, 1, 0, 2, 0, 0, 0, 0},
+	{0, 0, 0, 5, 0, 7, 0, 0, 0},
+	{0, 0, 4, 0, 0, 0, 1, 0, 0},
+	{0, 9, 0, 0, 0, 0, 0, 0, 0},
+	{5, 0, 0, 0, 0, 0, 0, 7, 3},
+	{0, 0, 2, 0, 1, 0, 0, 0, 0},
+	{0, 0, 0, 0, 4, 0, 0, 0, 9},
+}
+
+// TestStrategyByName verifies strategy lookup by command-line name
+func TestStrategyByName(t *testing.T) {
+	for _, name := range []string{"first", "mrv", "mrv-degree"} {
+		if _, err := solver.StrategyByName(name); err != nil {
+			t.Errorf("StrategyByName(%q) unexpected error: %v", name, err)
+		}
+	}
+
+	if _, err := solver.StrategyByName("random"); err == nil {
+		t.Errorf("StrategyByName(\"random\") expected error, got nil")
+	}
+}
+
+// TestStrategies_SolveExample verifies that every strategy finds the same
+// unique solution of the example puzzle
+func TestStrategies_SolveExample(t *testing.T) {
+	expected := utils.Board{
+		{3, 9, 6, 2, 4, 5, 7, 8, 1},
+		{1, 7, 8, 3, 6, 9, 5, 2, 4},
+		{5, 2, 4, 8, 1, 7, 3, 9, 6},
+		{2, 8, 7, 9, 5, 1, 6, 4, 3},
+		{9, 3, 1, 4, 8, 6, 2, 7, 5},
+		{4, 6, 5, 7, 2, 3, 9, 1, 8},
+		{7, 1, 2, 6, 3, 8, 4, 5, 9},
+		{6, 5, 9, 1, 7, 4, 8, 3, 2},
+		{8, 4, 3, 5, 9, 2, 1, 6, 7},
+	}
+
+	for _, name := range solver.StrategyNames() {
+		t.Run(name, func(t *testing.T) {
+			strategy, _ := solver.StrategyByName(name)
+			board := examplePuzzle
+
+			if !(&solver.Backtracker{Strategy: strategy}).Solve(&board) {
+				t.Fatalf("Solve() = false with strategy %s, expected true", name)
+			}
+			if board != expected {
+				t.Errorf("Solve() with strategy %s returned a wrong solution", name)
+			}
+		})
+	}
+}
+
+// TestMinRemaining_PicksMostConstrainedCell verifies that MRV branches on
+// the cell with the fewest candidates instead of the first empty one
+func TestMinRemaining_PicksMostConstrainedCell(t *testing.T) {
+	board := utils.NewBoard()
+
+	// Row 8 holds 1-8 in columns 0-7, leaving (8, 8) with a single candidate
+	for col := 0; col < 8; col++ {
+		board[8][col] = col + 1
+	}
+	tracker := validator.NewTracker(&board)
+
+	if row, col := solver.FirstEmpty(tracker); row != 0 || col != 0 {
+		t.Errorf("FirstEmpty() = (%d, %d), expected (0, 0)", row, col)
+	}
+	if row, col := solver.MinRemaining(tracker); row != 8 || col != 8 {
+		t.Errorf("MinRemaining() = (%d, %d), expected (8, 8)", row, col)
+	}
+	if row, col := solver.MinRemainingDegree(tracker); row != 8 || col != 8 {
+		t.Errorf("MinRemainingDegree() = (%d, %d), expected (8, 8)", row, col)
+	}
+}
+
+// TestSolve_AntiBruteForcePuzzle verifies that the default MRV strategy
+// solves a puzzle built to defeat reading-order backtracking
+func TestSolve_AntiBruteForcePuzzle(t *testing.T) {
+	board := antiBruteForce
+	if !solver.Solve(&board) {
+		t.Fatalf("Solve() = false on anti brute force puzzle, expected true")
+	}
+
+	expectedRow := [9]int{9, 8, 7, 6, 5, 4, 3, 2, 1}
+	if board[0] != expectedRow {
+		t.Errorf("Solve() first row = %v, expected %v", board[0], expectedRow)
+	}
+}
+
+// benchmarkStrategy runs the backtracker with a named strategy on a puzzle
+func benchmarkStrategy(b *testing.B, puzzle utils.Board, name string) {
+	strategy, err := solver.StrategyByName(name)
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkSolve(b, puzzle, (&solver.Backtracker{Strategy: strategy}).Solve)
+}
+
+// BenchmarkStrategy_VeryHardPuzzle compares cell selection strategies on Al Escargot
+func BenchmarkStrategy_VeryHardPuzzle(b *testing.B) {
+	for _, name := range solver.StrategyNames() {
+		b.Run(name, func(b *testing.B) {
+			benchmarkStrategy(b, alEscargot, name)
+		})
+	}
+}
+
+// BenchmarkStrategy_AntiBruteForce compares cell selection strategies on a
+// puzzle designed against reading-order search ("first" takes seconds)
+func BenchmarkStrategy_AntiBruteForce(b *testing.B) {
+	for _, name := range solver.StrategyNames() {
+		b.Run(name, func(b *testing.B) {
+			benchmarkStrategy(b, antiBruteForce, name)
+		})
+	}
+}
+
+// backends lists every Solver implementation the shared tests run against
+var backends = []struct {
+	name   string
+	solver solver.Solver
+}{
+	{"backtracker", &solver.Backtracker{}},
+	{"propagate", &solver.Backtracker{Propagate: true}},
+	{"dlx", &solver.DLX{}},
+}
+
+// TestSolvers_AgreeOnPuzzles verifies that every backend finds the same
+// unique solutions and solution counts through the Solver interface
+func TestSolvers_AgreeOnPuzzles(t *testing.T) {
+	puzzles := []struct {
+		name  string
+		board utils.Board
+		count int
+	}{
+		{"Example puzzle", examplePuzzle, 1},
+		{"Al Escargot", alEscargot, 1},
+		{"Anti brute force", antiBruteForce, 1},
+		{"Unsolvable puzzle", unsolvablePuzzle, 0},
+	}
+
+	for _, backend := range backends {
+		for _, puzzle := range puzzles {
+			t.Run(backend.name+"/"+puzzle.name, func(t *testing.T) {
+				board := puzzle.board
+				if count := backend.solver.CountSolutions(&board, 2); count != puzzle.count {
+					t.Fatalf("CountSolutions() = %d, expected %d", count, puzzle.count)
+				}
+				if board != puzzle.board {
+					t.Errorf("CountSolutions() modified the board, expected unchanged")
+				}
+
+				solved := backend.solver.Solve(&board)
+				if solved != (puzzle.count == 1) {
+					t.Fatalf("Solve() = %v, expected %v", solved, puzzle.count == 1)
+				}
+				if solved && len(validator.ValidateBoard(&board)) != 0 {
+					t.Errorf("Solve() returned a board that breaks the rules")
+				}
+			})
+		}
+	}
+}
+
+// TestSolvers_RejectInconsistentGivens verifies that no backend "solves"
+// a board with duplicate givens
+func TestSolvers_RejectInconsistentGivens(t *testing.T) {
+	board := utils.NewBoard()
+	board[0][0] = 3
+	board[8][0] = 3
+
+	for _, backend := range backends {
+		if count := backend.solver.CountSolutions(&board, 2); count != 0 {
+			t.Errorf("%s CountSolutions() = %d on inconsistent board, expected 0",
+				backend.name, count)
+		}
+	}
+}
+
+// TestDLX_EnumerateAllSolutions verifies that enumeration visits every solution
+// of an ambiguous puzzle and can be stopped early
+func TestDLX_EnumerateAllSolutions(t *testing.T) {
+	// Solved example with the swappable 4-cell pattern at rows 6-7, cols 5-6 removed
+	board := utils.Board{
+		{3, 9, 6, 2, 4, 5, 7, 8, 1},
+		{1, 7, 8, 3, 6, 9, 5, 2, 4},
+		{5, 2, 4, 8, 1, 7, 3, 9, 6},
+		{2, 8, 7, 9, 5, 1, 6, 4, 3},
+		{9, 3, 1, 4, 8, 6, 2, 7, 5},
+		{4, 6, 5, 7, 2, 3, 9, 1, 8},
+		{7, 1, 2, 6, 3, 0, 0, 5, 9},
+		{6, 5, 9, 1, 7, 0, 0, 3, 2},
+		{8, 4, 3, 5, 9, 2, 1, 6, 7},
+	}
+
+	seen := 0
+	(&solver.DLX{}).Enumerate(&board, func(solution utils.Board) bool {
+		seen++
+		if len(validator.ValidateBoard(&solution)) != 0 {
+			t.Errorf("Enumerate() visited a board that breaks the rules")
+		}
+		return true
+	})
+	if seen != 2 {
+		t.Errorf("Enumerate() visited %d solutions, expected 2", seen)
+	}
+
+	// Returning false from visit stops after the first solution
+	seen = 0
+	(&solver.DLX{}).Enumerate(&board, func(utils.Board) bool {
+		seen++
+		return false
+	})
+	if seen != 1 {
+		t.Errorf("Enumerate() visited %d solutions after stop, expected 1", seen)
+	}
+}
+
+// BenchmarkDLX_VeryHardPuzzle measures the exact cover solver on Al Escargot
+func BenchmarkDLX_VeryHardPuzzle(b *testing.B) {
+	benchmarkSolve(b, alEscargot, (&solver.DLX{}).Solve)
+}
+
+// BenchmarkDLX_AntiBruteForce measures the exact cover solver on a puzzle
+// designed against reading-order search
+func BenchmarkDLX_AntiBruteForce(b *testing.B) {
+	benchmarkSolve(b, antiBruteForce, (&solver.DLX{}).Solve)
+}
+
+// TestRegistry_BuiltInBackends verifies that the built-in backends are
+// registered and that New rejects unknown names
+func TestRegistry_BuiltInBackends(t *testing.T) {
+	for _, name := range []string{"backtrack", "propagate", "dlx"} {
+		backend, err := solver.New(name)
+		if err != nil {
+			t.Errorf("New(%q) unexpected error: %v", name, err)
+			continue
+		}
+
+		board := examplePuzzle
+		if !backend.Solve(&board) {
+			t.Errorf("New(%q).Solve() = false on example puzzle, expected true", name)
+		}
+	}
+
+	if _, err := solver.New("quantum"); err == nil {
+		t.Errorf("New(\"quantum\") expected error, got nil")
+	}
+}
+
+// TestRegistry_Register verifies that callers can add their own backends
+func TestRegistry_Register(t *testing.T) {
+	solver.Register("test-first", func() solver.Solver {
+		return &solver.Backtracker{Strategy: solver.FirstEmpty}
+	})
+
+	found := false
+	for _, name := range solver.Names() {
+		if name == "test-first" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Names() = %v, expected it to include test-first", solver.Names())
+	}
+
+	backend, err := solver.New("test-first")
+	if err != nil {
+		t.Fatalf("New(\"test-first\") unexpected error: %v", err)
+	}
+	board := examplePuzzle
+	if !backend.Solve(&board) {
+		t.Errorf("Registered backend Solve() = false, expected true")
+	}
+}
+
+// TestStats_CountWork verifies that each backend reports the work done by its
+// most recent call, and that propagation needs fewer nodes than plain search
+func TestStats_CountWork(t *testing.T) {
+	for _, backend := range backends {
+		board := alEscargot
+		backend.solver.Solve(&board)
+		if stats := backend.solver.Stats(); stats.Nodes == 0 {
+			t.Errorf("%s Stats().Nodes = 0 after solving, expected > 0", backend.name)
+		}
+
+		// A filled board never needs to backtrack
+		solved := board
+		backend.solver.Solve(&solved)
+		if stats := backend.solver.Stats(); stats.Backtracks != 0 {
+			t.Errorf("%s Stats().Backtracks = %d on solved board, expected 0",
+				backend.name, stats.Backtracks)
+		}
+	}
+
+	plain := &solver.Backtracker{}
+	propagating := &solver.Backtracker{Propagate: true}
+	for _, b := range []*solver.Backtracker{plain, propagating} {
+		board := alEscargot
+		b.Solve(&board)
+	}
+	if propagating.Stats().Nodes >= plain.Stats().Nodes {
+		t.Errorf("Propagating search visited %d nodes, expected fewer than plain %d",
+			propagating.Stats().Nodes, plain.Stats().Nodes)
+	}
+}
+
+// BenchmarkBackends_VeryHardPuzzle compares the registered backends on Al Escargot
+func BenchmarkBackends_VeryHardPuzzle(b *testing.B) {
+	for _, name := range []string{"backtrack", "propagate", "dlx"} {
+		b.Run(name, func(b *testing.B) {
+			backend, _ := solver.New(name)
+			benchmarkSolve(b, alEscargot, backend.Solve)
+		})
+	}
+}
+
+// TestSolveContext_Timeout verifies that a search too slow for its deadline
+// stops with ErrTimeout instead of running to completion
+func TestSolveContext_Timeout(t *testing.T) {
+	// Counting every solution of an empty board would never finish
+	slow := []struct {
+		name   string
+		solver solver.Solver
+	}{
+		{"backtracker", &solver.Backtracker{Strategy: solver.FirstEmpty}},
+		{"dlx", &solver.DLX{}},
+	}
+
+	for _, backend := range slow {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			board := utils.NewBoard()
+			start := time.Now()
+			_, err := backend.solver.FindSolutionsContext(ctx, &board, 0)
+
+			if !errors.Is(err, solver.ErrTimeout) {
+				t.Errorf("FindSolutionsContext() error = %v, expected ErrTimeout", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("FindSolutionsContext() took %v after a 20ms deadline", elapsed)
+			}
+		})
+	}
+}
+
+// TestSolveContext_Canceled verifies that an already-canceled context stops
+// the search before any work and reports context.Canceled, not a timeout
+func TestSolveContext_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	board := examplePuzzle
+	solved, err := solver.SolveContext(ctx, &board)
+	if solved {
+		t.Errorf("SolveContext() = true with canceled context, expected false")
+	}
+	if !errors.Is(err, context.Canceled) || errors.Is(err, solver.ErrTimeout) {
+		t.Errorf("SolveContext() error = %v, expected context.Canceled", err)
+	}
+	if board != examplePuzzle {
+		t.Errorf("SolveContext() modified the board after cancellation")
+	}
+}
+
+// TestSolveContext_Completes verifies that a generous deadline behaves like Solve
+func TestSolveContext_Completes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	board := alEscargot
+	solved, err := solver.SolveContext(ctx, &board)
+	if err != nil || !solved {
+		t.Fatalf("SolveContext() = %v, %v, expected true, nil", solved, err)
+	}
+	if len(validator.ValidateBoard(&board)) != 0 {
+		t.Errorf("SolveContext() returned a board that breaks the rules")
+	}
+}
+
+// TestStats_DepthGuessesAndTime verifies the search shape statistics
+func TestStats_DepthGuessesAndTime(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			board := alEscargot
+			backend.solver.Solve(&board)
+			stats := backend.solver.Stats()
+
+			// Al Escargot cannot be solved without guessing
+			if stats.Guesses == 0 {
+				t.Errorf("Stats().Guesses = 0 on Al Escargot, expected > 0")
+			}
+			if stats.MaxDepth == 0 || stats.MaxDepth > 81 {
+				t.Errorf("Stats().MaxDepth = %d, expected 1-81", stats.MaxDepth)
+			}
+			if stats.Elapsed <= 0 {
+				t.Errorf("Stats().Elapsed = %v, expected > 0", stats.Elapsed)
+			}
+
+			// A new call starts from fresh counters
+			solved := board
+			backend.solver.Solve(&solved)
+			if after := backend.solver.Stats(); after.Guesses != 0 {
+				t.Errorf("Stats().Guesses = %d on solved board, expected 0", after.Guesses)
+			}
+		})
+	}
+}
+
+// TestStats_Propagations verifies that forced placements are counted
+// separately from guesses by the propagating backtracker
+func TestStats_Propagations(t *testing.T) {
+	// The example puzzle is solved by singles alone
+	board := examplePuzzle
+	propagating := &solver.Backtracker{Propagate: true}
+	propagating.Solve(&board)
+
+	stats := propagating.Stats()
+	if stats.Guesses != 0 {
+		t.Errorf("Stats().Guesses = %d on singles-only puzzle, expected 0", stats.Guesses)
+	}
+	if stats.Propagations != 41 {
+		t.Errorf("Stats().Propagations = %d, expected 41 (one per empty cell)", stats.Propagations)
+	}
+}
+
+// TestStats_String verifies the printed statistics format
+func TestStats_String(t *testing.T) {
+	stats := solver.Stats{Nodes: 12, Guesses: 5, Backtracks: 3, MaxDepth: 9, Propagations: 7}
+	output := stats.String()
+
+	for _, line := range []string{"Nodes:        12", "Guesses:      5", "Backtracks:   3",
+		"Max depth:    9", "Propagations: 7", "Elapsed:      0s"} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Stats.String() = %q, expected it to contain %q", output, line)
+		}
+	}
+}
+
+// TestBacktracker_RandomOrder verifies that a random digit order still gives
+// valid solutions, and that the same seed gives the same full grid
+func TestBacktracker_RandomOrder(t *testing.T) {
+	first := utils.NewBoard()
+	second := utils.NewBoard()
+	(&solver.Backtracker{Rand: rand.New(rand.NewSource(7))}).Solve(&first)
+	(&solver.Backtracker{Rand: rand.New(rand.NewSource(7))}).Solve(&second)
+
+	if row, _ := utils.FindEmptyCell(&first); row != -1 || len(validator.ValidateBoard(&first)) > 0 {
+		t.Fatalf("Solve() with Rand did not produce a valid full grid")
+	}
+	if first != second {
+		t.Errorf("Solve() with the same seed produced different grids")
+	}
+
+	board, expected := examplePuzzle, examplePuzzle
+	solver.Solve(&expected)
+	(&solver.Backtracker{Rand: rand.New(rand.NewSource(7))}).Solve(&board)
+	if board != expected {
+		t.Errorf("Solve() with Rand changed the unique solution")
+	}
+}
+
+// checkGrid verifies that a solved grid is complete, breaks no rule and
+// keeps the givens of the puzzle
+func checkGrid(t *testing.T, puzzle, solution *utils.Grid) {
+	t.Helper()
+	for row := 0; row < puzzle.Size(); row++ {
+		for col := 0; col < puzzle.Size(); col++ {
+			value := solution.Cells[row][col]
+			if value < 1 || value > puzzle.Size() {
+				t.Fatalf("solution (%d, %d) = %d, expected 1-%d", row, col, value, puzzle.Size())
+			}
+			if given := puzzle.Cells[row][col]; given != 0 && given != value {
+				t.Errorf("solution changed given at (%d, %d)", row, col)
+			}
+		}
+	}
+	if conflicts := validator.ValidateGrid(solution); len(conflicts) > 0 {
+		t.Errorf("solution breaks a rule: %v", conflicts[0])
+	}
+}
+
+// TestSolveGrid_Shapes verifies that grids of every supported shape are solved
+func TestSolveGrid_Shapes(t *testing.T) {
+	for _, size := range []string{"4", "6", "8", "9", "12", "16", "25", "3x2"} {
+		shape, _ := utils.ParseShape(size)
+		puzzle := utils.NewGrid(shape)
+		puzzle.Cells[0][0] = shape.Size() // Largest digit in the corner
+
+		grid := puzzle.Clone()
+		if !solver.SolveGrid(grid) {
+			t.Errorf("SolveGrid(%s) = false, expected true", size)
+			continue
+		}
+		checkGrid(t, puzzle, grid)
+		if puzzle.Cells[0][1] != 0 {
+			t.Errorf("SolveGrid(%s) modified the puzzle it was cloned from", size)
+		}
+	}
+}
+
+// TestCountGridSolutions verifies uniqueness checks on small grids and
+// that inconsistent givens have no solution
+func TestCountGridSolutions(t *testing.T) {
+	shape := utils.Shape{BoxRows: 2, BoxCols: 2}
+	solution := utils.NewGrid(shape)
+	solution.Cells = [][]int{{1, 2, 3, 4}, {3, 4, 1, 2}, {2, 1, 4, 3}, {4, 3, 2, 1}}
+
+	// Removing one cell keeps a single solution; an empty 4x4 grid has 288
+	puzzle := solution.Clone()
+	puzzle.Cells[1][1] = 0
+	if count := solver.CountGridSolutions(puzzle, 0); count != 1 {
+		t.Errorf("CountGridSolutions(one empty cell) = %d, expected 1", count)
+	}
+	if count := solver.CountGridSolutions(utils.NewGrid(shape), 0); count != 288 {
+		t.Errorf("CountGridSolutions(empty 4x4) = %d, expected 288", count)
+	}
+
+	invalid := utils.NewGrid(shape)
+	invalid.Cells[0][0], invalid.Cells[0][3] = 2, 2
+	if count := solver.CountGridSolutions(invalid, 0); count != 0 {
+		t.Errorf("CountGridSolutions(invalid) = %d, expected 0", count)
+	}
+}
+
+// xPuzzle has a unique solution as Sudoku-X but several as classic sudoku
+var xPuzzle = mustParse(".1..9.... 5...1.... 73.....5. ....8.9.. ........5 6...4.... .9..2...7 .4.1..... ...3....1")
+
+// TestSolvers_SudokuX verifies that every backend uses the diagonals to
+// find the single Sudoku-X solution
+func TestSolvers_SudokuX(t *testing.T) {
+	if count := solver.CountSolutions(&xPuzzle, 2); count != 2 {
+		t.Fatalf("classic CountSolutions() = %d, expected 2 (puzzle needs the diagonals)", count)
+	}
+
+	variant := validator.Diagonals()
+	backends := []solver.Solver{
+		&solver.Backtracker{Variant: variant},
+		&solver.Backtracker{Variant: variant, Propagate: true},
+		&solver.DLX{Variant: variant},
+	}
+	for _, backend := range backends {
+		solutions := backend.FindSolutions(&xPuzzle, 2)
+		if len(solutions) != 1 {
+			t.Errorf("%T FindSolutions() = %d solutions, expected 1", backend, len(solutions))
+			continue
+		}
+		if conflicts := validator.ValidateVariant(&solutions[0], variant); len(conflicts) > 0 {
+			t.Errorf("%T solution breaks a rule: %v", backend, conflicts[0])
+		}
+		if row, _ := utils.FindEmptyCell(&solutions[0]); row != -1 {
+			t.Errorf("%T solution is incomplete", backend)
+		}
+	}
+}
+
+// jigsawRows is an irregular region map, one letter per region
+var jigsawRows = []string{"AAABBBCCC", "AAAABBCFC", "ADEABBCFC", "DDEEEBFFC", "DDDDEBIFC", "DDEEEEIFF", "GGGGGHIFF", "GHHGHHIII", "GGHHHHIII"}
+
+// jigsawPuzzle has a unique solution with the regions of jigsawRows, and
+// none with 3x3 boxes
+var jigsawPuzzle = mustParse(".....95.7 ..4..5... 1.....2.. .....8... .65.....3 376...... .....234. ......1.. .........")
+
+// jigsawSolution is the solution of jigsawPuzzle
+var jigsawSolution = mustParse("632149587 784925631 148563279 213758964 965817423 376294815 591672348 457386192 829431756")
+
+// mustJigsaw builds the variant for jigsawRows
+func mustJigsaw(t *testing.T) *validator.Variant {
+	t.Helper()
+	boxes, err := parser.ParseRegions(jigsawRows)
+	if err != nil {
+		t.Fatalf("ParseRegions() error = %v", err)
+	}
+	variant, err := validator.Jigsaw(boxes)
+	if err != nil {
+		t.Fatalf("Jigsaw() error = %v", err)
+	}
+	return variant
+}
+
+// TestMinRemainingDegree_Variants verifies that the degree tiebreak counts
+// the peers of jigsaw boxes and of extra regions, not of the 3x3 box alone
+func TestMinRemainingDegree_Variants(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant *validator.Variant
+	}{
+		{"jigsaw", mustJigsaw(t)},
+		{"windoku", validator.Windows()},
+		{"x", validator.Diagonals()},
+	}
+
+	for _, test := range tests {
+		variant := test.variant
+		board := utils.NewBoard()
+
+		// On an empty board every cell ties on candidates, so the first cell
+		// with the most peers in its row, column, box and regions wins
+		expected, best := validator.Cell{}, -1
+		for row := 0; row < 9; row++ {
+			for col := 0; col < 9; col++ {
+				units := [][]validator.Cell{validator.RowCells(row), validator.ColCells(col), variant.BoxCells(variant.BoxIndex(row, col))}
+				for _, index := range variant.RegionsOf(row, col) {
+					units = append(units, variant.Regions[index].Cells)
+				}
+				peers := map[validator.Cell]bool{}
+				for _, unit := range units {
+					for _, cell := range unit {
+						peers[cell] = true
+					}
+				}
+				if count := len(peers) - 1; count > best {
+					expected, best = validator.Cell{Row: row, Col: col}, count
+				}
+			}
+		}
+		if expected == (validator.Cell{}) {
+			t.Fatalf("%s: (0, 0) has the most peers, expected another cell", test.name)
+		}
+
+		tracker := validator.NewVariantTracker(&board, variant)
+		if row, col := solver.MinRemainingDegree(tracker); row != expected.Row || col != expected.Col {
+			t.Errorf("%s: MinRemainingDegree() = (%d, %d), expected (%d, %d)", test.name, row, col, expected.Row, expected.Col)
+		}
+	}
+}
+
+// TestSolvers_Jigsaw verifies that every backend solves with irregular
+// regions in place of the 3x3 boxes, alone and combined with the diagonals
+func TestSolvers_Jigsaw(t *testing.T) {
+	if count := solver.CountSolutions(&jigsawPuzzle, 2); count != 0 {
+		t.Fatalf("classic CountSolutions() = %d, expected 0", count)
+	}
+
+	variant := mustJigsaw(t)
+	backends := []solver.Solver{
+		&solver.Backtracker{Variant: variant},
+		&solver.Backtracker{Variant: variant, Propagate: true},
+		&solver.DLX{Variant: variant},
+	}
+	for _, backend := range backends {
+		solutions := backend.FindSolutions(&jigsawPuzzle, 2)
+		if len(solutions) != 1 || solutions[0] != jigsawSolution {
+			t.Errorf("%T FindSolutions() = %v, expected only %v", backend, solutions, jigsawSolution)
+		}
+	}
+
+	// The solution does not hold the diagonals, so adding them leaves none
+	combined := validator.Combine(variant, validator.Diagonals())
+	if count := (&solver.DLX{Variant: combined}).CountSolutions(&jigsawPuzzle, 2); count != 0 {
+		t.Errorf("jigsaw,x CountSolutions() = %d, expected 0", count)
+	}
+}
+
+// killerCages describes a killer puzzle whose only solution is killerSolution
+const killerCages = `# Cages of a killer puzzle with no givens
+14: r4c7 r5c7
+17: r9c8 r9c7 r9c9 r8c9
+21: r8c5 r9c5 r8c4 r9c4
+21: r9c2 r9c1 r8c2 r8c3
+13: r2c4 r2c5 r2c3
+9: r7c5 r6c5
+26: r2c1 r3c1 r4c1 r2c2
+21: r1c9 r2c9 r3c9
+15: r7c7 r7c8
+3: r7c4 r6c4
+12: r2c6 r1c6 r2c7
+14: r7c9 r6c9 r5c9
+19: r6c8 r5c8 r6c7
+16: r6c1 r6c2 r7c2
+20: r8c6 r9c6 r8c7 r8c8
+3: r5c1 r5c2
+11: r4c9 r4c8 r3c8 r3c7
+16: r3c2 r3c3 r4c3
+24: r4c4 r3c4 r5c4 r4c5
+26: r5c5 r5c6 r6c6 r7c6
+3: r1c3 r1c2
+9: r4c2
+8: r8c1 r7c1
+9: r3c5 r3c6 r4c6
+16: r2c8 r1c8 r1c7
+16: r5c3 r6c3 r7c3
+4: r1c1
+14: r1c4 r1c5
+5: r9c3
+`
+
+// killerSolution is the solution of the puzzle in killerCages
+var killerSolution = mustParse("412597368 568413279 739862154 894671532 127385946 356249781 283154697 671938425 945726813")
+
+// mustKiller builds the variant for killerCages
+func mustKiller(t *testing.T) *validator.Variant {
+	t.Helper()
+	cages, err := parser.ParseCages(killerCages)
+	if err != nil {
+		t.Fatalf("ParseCages() error = %v", err)
+	}
+	variant, err := validator.Killer(cages)
+	if err != nil {
+		t.Fatalf("Killer() error = %v", err)
+	}
+	return variant
+}
+
+// TestSolvers_Killer verifies that the backtracking backends solve a killer
+// puzzle from its cage sums alone
+func TestSolvers_Killer(t *testing.T) {
+	variant := mustKiller(t)
+	empty := utils.NewBoard()
+
+	backends := []solver.Solver{
+		&solver.Backtracker{Variant: variant},
+		&solver.Backtracker{Variant: variant, Propagate: true},
+	}
+	for _, backend := range backends {
+		solutions := backend.FindSolutions(&empty, 2)
+		if len(solutions) != 1 || solutions[0] != killerSolution {
+			t.Errorf("%T FindSolutions() = %v, expected only %v", backend, solutions, killerSolution)
+		}
+	}
+}
+
+// lineClues describes a puzzle with the thermo, arrows and sandwich sums of
+// killerSolution; with lineCluesPuzzle it has no other solution
+const lineClues = `# Thermo, arrows and every sandwich sum of killerSolution
+thermo r7c4 r7c5 r8c6
+arrow r3c3 r2c2 r1c2 r1c3
+arrow r4c2 r5c1 r5c2 r6c3
+sandwich row 1 7
+sandwich row 2 12
+sandwich row 3 16
+sandwich row 4 17
+sandwich row 5 25
+sandwich row 6 15
+sandwich row 7 15
+sandwich row 8 0
+sandwich row 9 32
+sandwich column 1 11
+sandwich column 2 9
+sandwich column 3 20
+sandwich column 4 0
+sandwich column 5 0
+sandwich column 6 5
+sandwich column 7 5
+sandwich column 8 2
+sandwich column 9 12
+`
+
+// lineCluesPuzzle holds the givens of the lineClues puzzle
+var lineCluesPuzzle = mustParse("4........ .6....... ..9.6.... ...6..... ....8.... .....9... ......6.. .......2. ........3")
+
+// mustLineClues builds the variant for lineClues
+func mustLineClues(t *testing.T) *validator.Variant {
+	t.Helper()
+	clues, err := parser.ParseClues(lineClues)
+	if err != nil {
+		t.Fatalf("ParseClues() error = %v", err)
+	}
+	variant, err := clues.Variant()
+	if err != nil {
+		t.Fatalf("Variant() error = %v", err)
+	}
+	return variant
+}
+
+// TestSolvers_LineClues verifies that the backtracking backends solve a
+// puzzle of thermo, arrow and sandwich clues
+func TestSolvers_LineClues(t *testing.T) {
+	variant := mustLineClues(t)
+
+	backends := []solver.Solver{
+		&solver.Backtracker{Variant: variant},
+		&solver.Backtracker{Variant: variant, Propagate: true},
+	}
+	for _, backend := range backends {
+		solutions := backend.FindSolutions(&lineCluesPuzzle, 2)
+		if len(solutions) != 1 || solutions[0] != killerSolution {
+			t.Errorf("%T FindSolutions() = %v, expected only %v", backend, solutions, killerSolution)
+		}
+	}
+}
+
+// TestSolvers_Constraint verifies that the backtracking backends enforce a
+// caller's constraint with no change to the solver
+func TestSolvers_Constraint(t *testing.T) {
+	variant := validator.NewConstraintVariant("even-diagonal", evenDiagonal{})
+	empty := utils.NewBoard()
+
+	backends := []solver.Solver{
+		&solver.Backtracker{Variant: variant},
+		&solver.Backtracker{Variant: variant, Propagate: true},
+	}
+	for _, backend := range backends {
+		board := empty
+		if !backend.Solve(&board) {
+			t.Fatalf("%T Solve() = false, expected true", backend)
+		}
+		if conflicts := validator.ValidateVariant(&board, variant); len(conflicts) > 0 {
+			t.Errorf("%T Solve() broke the constraint: %v", backend, conflicts)
+		}
+	}
+}
+
+// TestSolvers_RegisteredConstraint verifies that the backtracking backends
+// enforce a registered constraint on a classic board, and DLX refuses it
+func TestSolvers_RegisteredConstraint(t *testing.T) {
+	validator.RegisterConstraint("even-diagonal", evenDiagonal{})
+	t.Cleanup(func() { validator.RegisterConstraint("even-diagonal", nil) })
+
+	for _, backend := range []solver.Solver{&solver.Backtracker{}, &solver.Backtracker{Propagate: true}} {
+		board := utils.NewBoard()
+		if !backend.Solve(&board) {
+			t.Fatalf("%T Solve() = false, expected true", backend)
+		}
+		for i := 0; i < 9; i++ {
+			if board[i][i]%2 == 1 {
+				t.Errorf("%T Solve() put %d at (%d, %d), expected an even digit", backend, board[i][i], i, i)
+			}
+		}
+	}
+
+	empty := utils.NewBoard()
+	if _, err := (&solver.DLX{}).FindSolutionsContext(context.Background(), &empty, 1); !errors.Is(err, solver.ErrUnsupported) {
+		t.Errorf("DLX FindSolutionsContext() error = %v, expected ErrUnsupported", err)
+	}
+}
+
+// TestSolvers_AntiChess verifies that every backend enforces the knight and
+// king move rules, alone and combined
+func TestSolvers_AntiChess(t *testing.T) {
+	tests := []struct {
+		variants string
+		puzzle   utils.Board
+	}{
+		{"anti-knight", mustParse("..8...1.. 3..5..... .....2.3. ..1....5. ....5.... .5.....6. ...8...7. 2.9...... ....2..4.")},
+		{"anti-king", mustParse(".......2. ...41.... ....59... 6.9.4.... .576...8. ...8..... ..5...... 3.....9.. ..6..3...")},
+		{"anti-knight,anti-king", mustParse("5........ .1.5...3. 6...1.... ......... ..18....3 3..2..... ...3...4. .....5..7 ....2...5")},
+	}
+
+	for _, test := range tests {
+		variant, err := validator.ParseVariants(test.variants)
+		if err != nil {
+			t.Fatalf("ParseVariants(%q) error = %v", test.variants, err)
+		}
+		if count := solver.CountSolutions(&test.puzzle, 2); count != 2 {
+			t.Errorf("%s: classic CountSolutions() = %d, expected 2", test.variants, count)
+		}
+
+		backends := []solver.Solver{
+			&solver.Backtracker{Variant: variant},
+			&solver.Backtracker{Variant: variant, Propagate: true},
+			&solver.DLX{Variant: variant},
+		}
+		for _, backend := range backends {
+			solutions := backend.FindSolutions(&test.puzzle, 2)
+			if len(solutions) != 1 {
+				t.Errorf("%s: %T FindSolutions() = %d solutions, expected 1", test.variants, backend, len(solutions))
+				continue
+			}
+			if conflicts := validator.ValidateVariant(&solutions[0], variant); len(conflicts) > 0 {
+				t.Errorf("%s: %T solution breaks a rule: %v", test.variants, backend, conflicts[0])
+			}
+		}
+	}
+}
+
+// TestSolvers_NonConsecutive verifies that the backtracking backends keep
+// consecutive digits apart
+func TestSolvers_NonConsecutive(t *testing.T) {
+	puzzle := mustParse(".....73.. ....8.1.. ..6.3.... ..1...25. 6..7.1... ....2.... ......... 9.....7.. .6...2...")
+	variant := validator.NonConsecutive()
+
+	backends := []solver.Solver{
+		&solver.Backtracker{Variant: variant},
+		&solver.Backtracker{Variant: variant, Propagate: true},
+	}
+	for _, backend := range backends {
+		solutions := backend.FindSolutions(&puzzle, 2)
+		if len(solutions) != 1 {
+			t.Errorf("%T FindSolutions() = %d solutions, expected 1", backend, len(solutions))
+			continue
+		}
+		if conflicts := validator.ValidateVariant(&solutions[0], variant); len(conflicts) > 0 {
+			t.Errorf("%T solution breaks a rule: %v", backend, conflicts[0])
+		}
+	}
+}
+
+// TestSolvers_Kropki verifies that every dot of a solution, with the
+// negative rule, pins down that solution on an empty board
+func TestSolvers_Kropki(t *testing.T) {
+	// Place a dot wherever killerSolution has a consecutive or 1:2 pair
+	var dots []validator.Dot
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			for _, next := range []validator.Cell{{Row: row, Col: col + 1}, {Row: row + 1, Col: col}} {
+				if next.Row > 8 || next.Col > 8 {
+					continue
+				}
+				a, b := killerSolution[row][col], killerSolution[next.Row][next.Col]
+				switch {
+				case a-b == 1 || b-a == 1:
+					dots = append(dots, validator.Dot{A: validator.Cell{Row: row, Col: col}, B: next})
+				case a == 2*b || b == 2*a:
+					dots = append(dots, validator.Dot{Black: true, A: validator.Cell{Row: row, Col: col}, B: next})
+				}
+			}
+		}
+	}
+
+	variant, err := validator.Kropki(dots, true)
+	if err != nil {
+		t.Fatalf("Kropki() error = %v", err)
+	}
+	empty := utils.NewBoard()
+	for _, backend := range []solver.Solver{&solver.Backtracker{Variant: variant}, &solver.Backtracker{Variant: variant, Propagate: true}} {
+		solutions := backend.FindSolutions(&empty, 2)
+		if len(solutions) != 1 || solutions[0] != killerSolution {
+			t.Errorf("%T FindSolutions() = %d solutions, expected only killerSolution", backend, len(solutions))
+		}
+	}
+}
+
+// TestDLX_Unsupported verifies that DLX refuses every variant whose rules
+// are not exact cover constraints, leaving them to the backtracking backends
+func TestDLX_Unsupported(t *testing.T) {
+	thermo, err := validator.Clues{Thermos: [][]validator.Cell{{{Row: 0, Col: 0}, {Row: 0, Col: 1}}}}.Variant()
+	if err != nil {
+		t.Fatalf("Variant() error = %v", err)
+	}
+	dots, err := validator.Kropki([]validator.Dot{{A: validator.Cell{Row: 0, Col: 0}, B: validator.Cell{Row: 0, Col: 1}}}, false)
+	if err != nil {
+		t.Fatalf("Kropki() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		variant *validator.Variant
+	}{
+		{"killer", mustKiller(t)},
+		{"line clues", mustLineClues(t)},
+		{"thermo", thermo},
+		{"kropki", dots},
+		{"non-consecutive", validator.NonConsecutive()},
+		{"constraint", validator.NewConstraintVariant("even-diagonal", evenDiagonal{})},
+	}
+
+	empty := utils.NewBoard()
+	for _, test := range tests {
+		_, err := (&solver.DLX{Variant: test.variant}).FindSolutionsContext(context.Background(), &empty, 1)
+		if !errors.Is(err, solver.ErrUnsupported) {
+			t.Errorf("%s: DLX FindSolutionsContext() error = %v, expected ErrUnsupported", test.name, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package test
 
 import (
+	"errors"
 	"sudoku/utils"
 	"sudoku/validator"
 	"testing"
@@ -377,3 +378,784 @@ func TestIsValid_FilledBox(t *testing.T) {
 		t.Errorf("IsValid(3, 3, 1) = false, expected true (different box)")
 	}
 }
+
+// TestValidateBoard_ConsistentBoards verifies that boards without
+// duplicate givens produce no conflicts
+func TestValidateBoard_ConsistentBoards(t *testing.T) {
+	empty := utils.NewBoard()
+	if conflicts := validator.ValidateBoard(&empty); len(conflicts) != 0 {
+		t.Errorf("ValidateBoard() on empty board = %v, expected no conflicts", conflicts)
+	}
+
+	example := utils.Board{
+		{0, 9, 6, 0, 4, 0, 0, 0, 1},
+		{1, 0, 0, 0, 6, 0, 0, 0, 4},
+		{5, 0, 4, 8, 1, 0, 3, 9, 0},
+		{0, 0, 7, 9, 5, 0, 0, 4, 3},
+		{0, 3, 0, 0, 8, 0, 0, 0, 0},
+		{4, 0, 5, 0, 2, 3, 0, 1, 8},
+		{0, 1, 0, 6, 3, 0, 0, 5, 9},
+		{0, 5, 9, 0, 7, 0, 8, 3, 0},
+		{0, 0, 3, 5, 9, 0, 0, 0, 7},
+	}
+	if conflicts := validator.ValidateBoard(&example); len(conflicts) != 0 {
+		t.Errorf("ValidateBoard() on example puzzle = %v, expected no conflicts", conflicts)
+	}
+}
+
+// TestValidateBoard_SingleConflicts verifies that each kind of duplicate
+// is reported with the right unit, index, digit and cells
+func TestValidateBoard_SingleConflicts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		a, b     validator.Cell
+		digit    int
+		expected validator.Conflict
+	}{
+		{
+			"Row conflict",
+			validator.Cell{Row: 2, Col: 1}, validator.Cell{Row: 2, Col: 7}, 4,
+			validator.Conflict{Unit: validator.UnitRow, Index: 2, Digit: 4,
+				First: validator.Cell{Row: 2, Col: 1}, Second: validator.Cell{Row: 2, Col: 7}},
+		},
+		{
+			"Column conflict",
+			validator.Cell{Row: 8, Col: 5}, validator.Cell{Row: 0, Col: 5}, 9,
+			validator.Conflict{Unit: validator.UnitColumn, Index: 5, Digit: 9,
+				First: validator.Cell{Row: 0, Col: 5}, Second: validator.Cell{Row: 8, Col: 5}},
+		},
+		{
+			"Box conflict",
+			validator.Cell{Row: 3, Col: 6}, validator.Cell{Row: 5, Col: 8}, 1,
+			validator.Conflict{Unit: validator.UnitBox, Index: 5, Digit: 1,
+				First: validator.Cell{Row: 3, Col: 6}, Second: validator.Cell{Row: 5, Col: 8}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			board := utils.NewBoard()
+			board[tc.a.Row][tc.a.Col] = tc.digit
+			board[tc.b.Row][tc.b.Col] = tc.digit
+
+			conflicts := validator.ValidateBoard(&board)
+			if len(conflicts) != 1 {
+				t.Fatalf("ValidateBoard() returned %d conflicts, expected 1: %v",
+					len(conflicts), conflicts)
+			}
+			if conflicts[0] != tc.expected {
+				t.Errorf("ValidateBoard() = %+v, expected %+v", conflicts[0], tc.expected)
+			}
+		})
+	}
+}
+
+// TestValidateBoard_MultipleConflicts verifies that a pair sharing a row
+// and a box is reported once per unit, and that the diagnostic is readable
+func TestValidateBoard_MultipleConflicts(t *testing.T) {
+	board := utils.NewBoard()
+	board[0][0] = 7
+	board[0][2] = 7
+
+	conflicts := validator.ValidateBoard(&board)
+	if len(conflicts) != 2 {
+		t.Fatalf("ValidateBoard() returned %d conflicts, expected 2 (row + box): %v",
+			len(conflicts), conflicts)
+	}
+	if conflicts[0].Unit != validator.UnitRow || conflicts[1].Unit != validator.UnitBox {
+		t.Errorf("ValidateBoard() units = %s, %s, expected row, box",
+			conflicts[0].Unit, conflicts[1].Unit)
+	}
+
+	expected := "duplicate 7 in row 0 at (0, 0) and (0, 2)"
+	if conflicts[0].String() != expected {
+		t.Errorf("Conflict.String() = %q, expected %q", conflicts[0].String(), expected)
+	}
+}
+
+// TestTracker_MatchesIsValid verifies that the bitmask tracker agrees with
+// IsValid for every empty cell and digit of a realistic puzzle
+func TestTracker_MatchesIsValid(t *testing.T) {
+	board := utils.Board{
+		{0, 9, 6, 0, 4, 0, 0, 0, 1},
+		{1, 0, 0, 0, 6, 0, 0, 0, 4},
+		{5, 0, 4, 8, 1, 0, 3, 9, 0},
+		{0, 0, 7, 9, 5, 0, 0, 4, 3},
+		{0, 3, 0, 0, 8, 0, 0, 0, 0},
+		{4, 0, 5, 0, 2, 3, 0, 1, 8},
+		{0, 1, 0, 6, 3, 0, 0, 5, 9},
+		{0, 5, 9, 0, 7, 0, 8, 3, 0},
+		{0, 0, 3, 5, 9, 0, 0, 0, 7},
+	}
+	tracker := validator.NewTracker(&board)
+
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if board[row][col] != 0 {
+				continue
+			}
+			for num := 1; num <= 9; num++ {
+				expected := validator.IsValid(&board, row, col, num)
+				if got := tracker.CanPlace(row, col, num); got != expected {
+					t.Errorf("CanPlace(%d, %d, %d) = %v, expected %v (IsValid)",
+						row, col, num, got, expected)
+				}
+			}
+		}
+	}
+}
+
+// TestTracker_PlaceAndRemove verifies that masks are updated incrementally
+// and that removing a digit restores the previous state
+func TestTracker_PlaceAndRemove(t *testing.T) {
+	board := utils.NewBoard()
+	tracker := validator.NewTracker(&board)
+
+	// Placing 5 at (4, 4) writes the board and blocks 5 in its row, column and box
+	tracker.Place(4, 4, 5)
+	if board[4][4] != 5 {
+		t.Errorf("Place(4, 4, 5) left board[4][4] = %d, expected 5", board[4][4])
+	}
+	blocked := []validator.Cell{{Row: 4, Col: 0}, {Row: 0, Col: 4}, {Row: 3, Col: 5}}
+	for _, cell := range blocked {
+		if tracker.CanPlace(cell.Row, cell.Col, 5) {
+			t.Errorf("CanPlace(%d, %d, 5) = true after Place(4, 4, 5), expected false",
+				cell.Row, cell.Col)
+		}
+	}
+	if got := validator.CountCandidates(tracker.Candidates(4, 0)); got != 8 {
+		t.Errorf("CountCandidates(4, 0) = %d, expected 8", got)
+	}
+
+	// Removing it frees the digit everywhere again
+	tracker.Remove(4, 4)
+	if board[4][4] != 0 {
+		t.Errorf("Remove(4, 4) left board[4][4] = %d, expected 0", board[4][4])
+	}
+	for _, cell := range blocked {
+		if !tracker.CanPlace(cell.Row, cell.Col, 5) {
+			t.Errorf("CanPlace(%d, %d, 5) = false after Remove(4, 4), expected true",
+				cell.Row, cell.Col)
+		}
+	}
+}
+
+// TestValidateGrid verifies conflict detection on a 4x4 grid, including
+// its 2x2 boxes
+func TestValidateGrid(t *testing.T) {
+	grid := utils.NewGrid(utils.Shape{BoxRows: 2, BoxCols: 2})
+	if conflicts := validator.ValidateGrid(grid); len(conflicts) != 0 {
+		t.Errorf("ValidateGrid(empty) = %v, expected none", conflicts)
+	}
+
+	// 3 twice in box 1 (top right), sharing no row or column
+	grid.Cells[0][2] = 3
+	grid.Cells[1][3] = 3
+	conflicts := validator.ValidateGrid(grid)
+	expected := validator.Conflict{
+		Unit: validator.UnitBox, Index: 1, Digit: 3,
+		First: validator.Cell{Row: 0, Col: 2}, Second: validator.Cell{Row: 1, Col: 3},
+	}
+	if len(conflicts) != 1 || conflicts[0] != expected {
+		t.Errorf("ValidateGrid() = %v, expected [%v]", conflicts, expected)
+	}
+}
+
+// TestValidateVariant_Diagonals verifies that Sudoku-X reports repeats on
+// either diagonal, which the classic rules allow
+func TestValidateVariant_Diagonals(t *testing.T) {
+	board := utils.NewBoard()
+	board[0][0], board[8][8] = 5, 5 // Main diagonal, no shared row, column or box
+	board[0][8], board[4][4] = 7, 7 // Anti-diagonal
+
+	if conflicts := validator.ValidateBoard(&board); len(conflicts) != 0 {
+		t.Errorf("ValidateBoard() = %v, expected none", conflicts)
+	}
+
+	conflicts := validator.ValidateVariant(&board, validator.Diagonals())
+	if len(conflicts) != 2 {
+		t.Fatalf("ValidateVariant() = %v, expected 2 conflicts", conflicts)
+	}
+	if conflicts[0].Unit != validator.UnitDiagonal || conflicts[0].Index != 0 || conflicts[0].Digit != 5 {
+		t.Errorf("ValidateVariant()[0] = %v, expected duplicate 5 in diagonal 0", conflicts[0])
+	}
+	if conflicts[1].Index != 1 || conflicts[1].Digit != 7 {
+		t.Errorf("ValidateVariant()[1] = %v, expected duplicate 7 in diagonal 1", conflicts[1])
+	}
+}
+
+// TestVariant_IsValidAndTracker verifies that placements on a diagonal
+// are checked by both Variant.IsValid and a variant Tracker
+func TestVariant_IsValidAndTracker(t *testing.T) {
+	variant := validator.Diagonals()
+	board := utils.NewBoard()
+	board[0][0] = 5
+	tracker := validator.NewVariantTracker(&board, variant)
+
+	tests := []struct {
+		row, col, num int
+		expected      bool
+	}{
+		{8, 8, 5, false}, // Same main diagonal
+		{8, 8, 4, true},
+		{7, 1, 5, true},  // Anti-diagonal does not hold 5
+		{1, 2, 5, false}, // Same box, classic rule
+	}
+
+	for _, test := range tests {
+		if result := variant.IsValid(&board, test.row, test.col, test.num); result != test.expected {
+			t.Errorf("IsValid(%d, %d, %d) = %v, expected %v", test.row, test.col, test.num, result, test.expected)
+		}
+		if result := tracker.CanPlace(test.row, test.col, test.num); result != test.expected {
+			t.Errorf("CanPlace(%d, %d, %d) = %v, expected %v", test.row, test.col, test.num, result, test.expected)
+		}
+	}
+
+	tracker.Remove(0, 0)
+	if !tracker.CanPlace(8, 8, 5) {
+		t.Errorf("CanPlace(8, 8, 5) after Remove(0, 0) = false, expected true")
+	}
+}
+
+// TestParseVariants verifies variant names and the classic default
+func TestParseVariants(t *testing.T) {
+	if variant, err := validator.ParseVariants("classic"); err != nil || variant != nil {
+		t.Errorf("ParseVariants(%q) = %v, %v, expected nil", "classic", variant, err)
+	}
+	variant, err := validator.ParseVariants("x")
+	if err != nil || variant.String() != "x" || len(variant.Regions) != 2 {
+		t.Errorf("ParseVariants(%q) = %v, %v, expected two diagonals", "x", variant, err)
+	}
+	variant, err = validator.ParseVariants("windoku, x")
+	if err != nil || variant.String() != "windoku,x" || len(variant.Units()) != 27+6 {
+		t.Errorf("ParseVariants(%q) = %v, %v, expected four windows and two diagonals", "windoku, x", variant, err)
+	}
+	if _, err := validator.ParseVariants("x,mystery"); err == nil {
+		t.Errorf("ParseVariants(%q) expected an error", "x,mystery")
+	}
+}
+
+// TestJigsaw_Regions verifies that region maps must be nine connected
+// regions of nine cells
+func TestJigsaw_Regions(t *testing.T) {
+	var boxes [9][9]int
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			boxes[row][col] = validator.BoxIndex(row, col)
+		}
+	}
+	if _, err := validator.Jigsaw(boxes); err != nil {
+		t.Errorf("Jigsaw(3x3 boxes) error = %v, expected nil", err)
+	}
+
+	tests := []struct {
+		name string
+		edit func(b *[9][9]int)
+	}{
+		{"region out of range", func(b *[9][9]int) { b[4][4] = 9 }},
+		{"uneven sizes", func(b *[9][9]int) { b[0][3] = 0 }},
+		{"disconnected", func(b *[9][9]int) { b[0][0], b[4][4] = 4, 0 }},
+	}
+	for _, test := range tests {
+		bad := boxes
+		test.edit(&bad)
+		if _, err := validator.Jigsaw(bad); !errors.Is(err, validator.ErrInvalidRegions) {
+			t.Errorf("Jigsaw(%s) error = %v, expected ErrInvalidRegions", test.name, err)
+		}
+	}
+}
+
+// TestValidateVariant_Jigsaw verifies that jigsaw regions replace the 3x3
+// boxes in conflicts and placement checks
+func TestValidateVariant_Jigsaw(t *testing.T) {
+	variant := mustJigsaw(t)
+	if conflicts := validator.ValidateVariant(&jigsawSolution, variant); len(conflicts) != 0 {
+		t.Errorf("ValidateVariant(solution) = %v, expected no conflicts", conflicts)
+	}
+	if conflicts := validator.ValidateBoard(&jigsawSolution); len(conflicts) == 0 {
+		t.Errorf("ValidateBoard(solution) found no conflicts, expected 3x3 box repeats")
+	}
+
+	// (1, 3) is in region A with (0, 0), not in the box of (0, 3)
+	board := utils.NewBoard()
+	board[0][0] = 5
+	board[0][4] = 7
+	if variant.IsValid(&board, 1, 3, 5) {
+		t.Errorf("IsValid(1, 3, 5) = true, expected false (same region)")
+	}
+	if !variant.IsValid(&board, 2, 3, 7) {
+		t.Errorf("IsValid(2, 3, 7) = false, expected true (3x3 box only)")
+	}
+	tracker := validator.NewVariantTracker(&board, variant)
+	if tracker.CanPlace(1, 3, 5) || !tracker.CanPlace(2, 3, 7) {
+		t.Errorf("CanPlace() disagrees with IsValid() on jigsaw regions")
+	}
+
+	board[1][3] = 5
+	conflicts := validator.ValidateVariant(&board, variant)
+	if len(conflicts) != 1 || conflicts[0].Unit != validator.UnitBox {
+		t.Errorf("ValidateVariant() = %v, expected one box conflict", conflicts)
+	}
+}
+
+// TestKiller_Cages verifies that impossible cage layouts are rejected
+func TestKiller_Cages(t *testing.T) {
+	pair := []validator.Cell{{Row: 0, Col: 0}, {Row: 0, Col: 1}}
+	tests := []struct {
+		name  string
+		cages []validator.Cage
+	}{
+		{"empty cage", []validator.Cage{{Sum: 5}}},
+		{"sum too small", []validator.Cage{{Sum: 2, Cells: pair}}},
+		{"sum too big", []validator.Cage{{Sum: 18, Cells: pair}}},
+		{"shared cell", []validator.Cage{{Sum: 3, Cells: pair}, {Sum: 4, Cells: pair[1:]}}},
+		{"off the board", []validator.Cage{{Sum: 4, Cells: []validator.Cell{{Row: 9, Col: 0}}}}},
+	}
+	for _, test := range tests {
+		if _, err := validator.Killer(test.cages); !errors.Is(err, validator.ErrInvalidCages) {
+			t.Errorf("Killer(%s) error = %v, expected ErrInvalidCages", test.name, err)
+		}
+	}
+}
+
+// TestVariant_CageSums verifies that cage sums limit placements and are
+// reported when the givens can no longer reach them
+func TestVariant_CageSums(t *testing.T) {
+	// r1c1 + r1c2 + r1c3 = 7 allows only 1, 2 and 4
+	cells := []validator.Cell{{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 0, Col: 2}}
+	variant, err := validator.Killer([]validator.Cage{{Sum: 7, Cells: cells}})
+	if err != nil {
+		t.Fatalf("Killer() error = %v", err)
+	}
+
+	board := utils.NewBoard()
+	tracker := validator.NewVariantTracker(&board, variant)
+	if mask := tracker.Candidates(0, 0); mask != 1<<1|1<<2|1<<4 {
+		t.Errorf("Candidates(0, 0) = %b, expected digits 1, 2 and 4", mask)
+	}
+
+	// After a 4 the other two cells must hold 1 and 2
+	tracker.Place(0, 0, 4)
+	for num := 1; num <= 9; num++ {
+		expected := num == 1 || num == 2
+		if result := variant.IsValid(&board, 0, 1, num); result != expected {
+			t.Errorf("IsValid(0, 1, %d) = %v, expected %v", num, result, expected)
+		}
+		if result := tracker.CanPlace(0, 1, num); result != expected {
+			t.Errorf("CanPlace(0, 1, %d) = %v, expected %v", num, result, expected)
+		}
+	}
+
+	board[0][2] = 5
+	conflicts := validator.ValidateVariant(&board, variant)
+	if len(conflicts) != 1 || conflicts[0].Unit != validator.UnitCage {
+		t.Fatalf("ValidateVariant() = %v, expected one cage conflict", conflicts)
+	}
+	expected := "sum 9 cannot reach 7 in cage 0 at (0, 0) and (0, 2)"
+	if conflicts[0].String() != expected {
+		t.Errorf("Conflict.String() = %q, expected %q", conflicts[0].String(), expected)
+	}
+
+	if conflicts := validator.ValidateVariant(&killerSolution, mustKiller(t)); len(conflicts) != 0 {
+		t.Errorf("ValidateVariant(killer solution) = %v, expected no conflicts", conflicts)
+	}
+}
+
+// TestWindows verifies the cells of the four Windoku windows
+func TestWindows(t *testing.T) {
+	variant := validator.Windows()
+	if len(variant.Regions) != 4 {
+		t.Fatalf("Windows() has %d regions, expected 4", len(variant.Regions))
+	}
+
+	tests := []struct {
+		row, col int
+		windows  []int
+	}{
+		{1, 1, []int{0}},
+		{3, 7, []int{1}},
+		{7, 3, []int{2}},
+		{5, 5, []int{3}},
+		{4, 4, nil}, // Centre lies between the windows
+		{0, 0, nil},
+	}
+	for _, test := range tests {
+		regions := variant.RegionsOf(test.row, test.col)
+		if len(regions) != len(test.windows) || (len(regions) == 1 && regions[0] != test.windows[0]) {
+			t.Errorf("RegionsOf(%d, %d) = %v, expected %v", test.row, test.col, regions, test.windows)
+		}
+	}
+
+	board := utils.NewBoard()
+	board[1][1] = 4
+	board[3][3] = 4
+	conflicts := validator.ValidateVariant(&board, variant)
+	if len(conflicts) != 1 || conflicts[0].String() != "duplicate 4 in window 0 at (1, 1) and (3, 3)" {
+		t.Errorf("ValidateVariant() = %v, expected a window conflict", conflicts)
+	}
+}
+
+// TestAntiKnightAndKing verifies the chess move pairs and their conflicts
+func TestAntiKnightAndKing(t *testing.T) {
+	knight, king := validator.AntiKnight(), validator.AntiKing()
+	if len(knight.Regions) != 224 || len(king.Regions) != 128 {
+		t.Errorf("pairs = %d knight, %d king, expected 224 and 128", len(knight.Regions), len(king.Regions))
+	}
+
+	board := utils.NewBoard()
+	board[3][3] = 6
+	tests := []struct {
+		variant  *validator.Variant
+		row, col int
+		expected bool
+	}{
+		{knight, 1, 4, false}, // Knight's move away
+		{knight, 4, 1, false},
+		{knight, 2, 2, true}, // King's move only
+		{king, 2, 2, false},
+		{king, 4, 2, false},
+		{king, 1, 4, true},
+	}
+	for _, test := range tests {
+		if result := test.variant.IsValid(&board, test.row, test.col, 6); result != test.expected {
+			t.Errorf("%v IsValid(%d, %d, 6) = %v, expected %v", test.variant, test.row, test.col, result, test.expected)
+		}
+		tracker := validator.NewVariantTracker(&board, test.variant)
+		if result := tracker.CanPlace(test.row, test.col, 6); result != test.expected {
+			t.Errorf("%v CanPlace(%d, %d, 6) = %v, expected %v", test.variant, test.row, test.col, result, test.expected)
+		}
+	}
+
+	board[2][5] = 6
+	conflicts := validator.ValidateVariant(&board, knight)
+	if len(conflicts) != 1 || conflicts[0].Unit != validator.UnitKnight {
+		t.Errorf("ValidateVariant() = %v, expected one knight move conflict", conflicts)
+	}
+}
+
+// TestKropki verifies dot checks, the negative rule and malformed dots
+func TestKropki(t *testing.T) {
+	a, b, c := validator.Cell{Row: 0, Col: 0}, validator.Cell{Row: 0, Col: 1}, validator.Cell{Row: 1, Col: 0}
+	variant, err := validator.Kropki([]validator.Dot{{A: a, B: b}, {Black: true, A: c, B: a}}, true)
+	if err != nil {
+		t.Fatalf("Kropki() error = %v", err)
+	}
+
+	board := utils.NewBoard()
+	board[0][0] = 4
+	tracker := validator.NewVariantTracker(&board, variant)
+	tests := []struct {
+		row, col int
+		allowed  []int
+	}{
+		{0, 1, []int{3, 5}},                   // White dot
+		{1, 0, []int{2, 8}},                   // Black dot
+		{1, 1, []int{1, 2, 3, 5, 6, 7, 8, 9}}, // Only the box rules out 4
+	}
+	for _, test := range tests {
+		var expected uint16
+		for _, num := range test.allowed {
+			expected |= 1 << num
+		}
+		if mask := tracker.Candidates(test.row, test.col); mask != expected {
+			t.Errorf("Candidates(%d, %d) = %b, expected %b", test.row, test.col, mask, expected)
+		}
+		for num := 1; num <= 9; num++ {
+			if result := variant.IsValid(&board, test.row, test.col, num); result != (expected&(1<<num) != 0) {
+				t.Errorf("IsValid(%d, %d, %d) = %v, expected %v", test.row, test.col, num, result, !result)
+			}
+		}
+	}
+
+	// (1, 1) has no dot, so the negative rule forbids 2 beside a 1 at (1, 2)
+	board[1][1], board[1][2] = 2, 1
+	conflicts := validator.ValidateVariant(&board, variant)
+	if len(conflicts) != 1 || conflicts[0].String() != "2 next to 1 in dotless pair 17 at (1, 1) and (1, 2)" {
+		t.Errorf("ValidateVariant() = %v, expected a dotless pair conflict", conflicts)
+	}
+
+	bad := [][]validator.Dot{
+		{{A: a, B: validator.Cell{Row: 1, Col: 1}}}, // Diagonal
+		{{A: a, B: b}, {Black: true, A: b, B: a}},   // Same cells twice
+		{{A: validator.Cell{Row: 8, Col: 8}, B: validator.Cell{Row: 8, Col: 9}}},
+	}
+	for _, dots := range bad {
+		if _, err := validator.Kropki(dots, false); !errors.Is(err, validator.ErrInvalidDots) {
+			t.Errorf("Kropki(%v) error = %v, expected ErrInvalidDots", dots, err)
+		}
+	}
+}
+
+// TestNonConsecutive verifies that orthogonal neighbours may not be consecutive
+func TestNonConsecutive(t *testing.T) {
+	variant := validator.NonConsecutive()
+	if len(variant.Pairs) != 144 {
+		t.Errorf("NonConsecutive() has %d pairs, expected 144", len(variant.Pairs))
+	}
+
+	board := utils.NewBoard()
+	board[4][4] = 5
+	tests := []struct {
+		row, col, num int
+		expected      bool
+	}{
+		{4, 5, 6, false},
+		{3, 4, 4, false},
+		{4, 5, 7, true},
+		{3, 3, 6, true}, // Diagonal neighbours may be consecutive
+	}
+	for _, test := range tests {
+		if result := variant.IsValid(&board, test.row, test.col, test.num); result != test.expected {
+			t.Errorf("IsValid(%d, %d, %d) = %v, expected %v", test.row, test.col, test.num, result, test.expected)
+		}
+	}
+}
+
+// TestLineClues verifies the candidates and conflicts of thermos, arrows
+// and sandwiches
+func TestLineClues(t *testing.T) {
+	clues := validator.Clues{
+		Thermos:    [][]validator.Cell{{{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 0, Col: 2}}},
+		Arrows:     []validator.Arrow{{Circle: validator.Cell{Row: 4, Col: 4}, Cells: []validator.Cell{{Row: 3, Col: 3}, {Row: 2, Col: 2}}}},
+		Sandwiches: []validator.Sandwich{{Index: 8, Sum: 0}},
+	}
+	variant, err := clues.Variant()
+	if err != nil {
+		t.Fatalf("Variant() error = %v", err)
+	}
+	if variant.Name != "thermo,arrow,sandwich" {
+		t.Errorf("Variant().Name = %q, expected \"thermo,arrow,sandwich\"", variant.Name)
+	}
+
+	board := utils.NewBoard()
+	board[0][1], board[3][3], board[8][0] = 5, 6, 1
+	tests := []struct {
+		row, col int
+		allowed  []int
+	}{
+		{0, 0, []int{2, 3, 4}},                   // Below the 5 on the thermo, 1 is in the column
+		{0, 2, []int{6, 7, 8, 9}},                // Above it
+		{4, 4, []int{7, 8, 9}},                   // Circle: 6 plus a digit
+		{2, 2, []int{1, 2, 3}},                   // Keeps the sum at most 9
+		{8, 1, []int{9}},                         // Sum 0: the 9 touches the 1
+		{8, 5, []int{2, 3, 4, 5, 6, 7, 8}},       // Outside the sandwich
+		{7, 7, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}}, // No clue
+	}
+	for _, test := range tests {
+		tracker := validator.NewVariantTracker(&board, variant)
+		var expected uint16
+		for _, num := range test.allowed {
+			expected |= 1 << num
+		}
+		if mask := tracker.Candidates(test.row, test.col); mask != expected {
+			t.Errorf("Candidates(%d, %d) = %b, expected %b", test.row, test.col, mask, expected)
+		}
+		for num := 1; num <= 9; num++ {
+			if result := variant.IsValid(&board, test.row, test.col, num); result != (expected&(1<<num) != 0) {
+				t.Errorf("IsValid(%d, %d, %d) = %v, expected %v", test.row, test.col, num, result, !result)
+			}
+		}
+	}
+
+	// A full circle pins the last arrow cell
+	board[4][4] = 8
+	if mask := validator.NewVariantTracker(&board, variant).Candidates(2, 2); mask != 1<<2 {
+		t.Errorf("Candidates(2, 2) = %b, expected %b", mask, 1<<2)
+	}
+
+	// Each broken clue is one conflict
+	board[0][2], board[2][2], board[8][3] = 3, 9, 9
+	expected := []string{
+		"5 then 3 in thermo 0 at (0, 1) and (0, 2)",
+		"circle cannot be the sum in arrow 0 at (4, 4) and (2, 2)",
+		"sum 0 cannot be made in sandwich 0 at (8, 0) and (8, 3)",
+	}
+	conflicts := validator.ValidateVariant(&board, variant)
+	if len(conflicts) != len(expected) {
+		t.Fatalf("ValidateVariant() = %v, expected %v", conflicts, expected)
+	}
+	for i, conflict := range conflicts {
+		if conflict.String() != expected[i] {
+			t.Errorf("ValidateVariant()[%d] = %q, expected %q", i, conflict.String(), expected[i])
+		}
+	}
+
+	bad := []validator.Clues{
+		{Thermos: [][]validator.Cell{{{Row: 0, Col: 0}}}},                                                                // Too short
+		{Thermos: [][]validator.Cell{{{Row: 0, Col: 0}, {Row: 0, Col: 2}}}},                                              // Gap
+		{Arrows: []validator.Arrow{{Circle: validator.Cell{Row: 0, Col: 0}, Cells: []validator.Cell{{Row: 0, Col: 0}}}}}, // Circle on the arrow
+		{Sandwiches: []validator.Sandwich{{Index: 9}}},
+		{Sandwiches: []validator.Sandwich{{Index: 0, Sum: 36}}},
+		{Sandwiches: []validator.Sandwich{{Column: true, Index: 2, Sum: 5}, {Column: true, Index: 2, Sum: 7}}},
+	}
+	for _, clues := range bad {
+		if _, err := clues.Variant(); !errors.Is(err, validator.ErrInvalidClues) {
+			t.Errorf("Variant(%v) error = %v, expected ErrInvalidClues", clues, err)
+		}
+	}
+}
+
+// evenDiagonal is a caller-supplied rule: the main diagonal holds only
+// even digits
+type evenDiagonal struct{}
+
+// Check reports whether num is even or off the diagonal
+func (evenDiagonal) Check(board *utils.Board, row, col, num int) bool {
+	return row != col || num%2 == 0
+}
+
+// Candidates returns the even digits for diagonal cells
+func (evenDiagonal) Candidates(board *utils.Board, row, col int) uint16 {
+	if row != col {
+		return validator.AllDigits
+	}
+	return 1<<2 | 1<<4 | 1<<6 | 1<<8
+}
+
+// Conflicts reports every odd digit on the diagonal
+func (evenDiagonal) Conflicts(board *utils.Board) []validator.Conflict {
+	var conflicts []validator.Conflict
+	for i := 0; i < 9; i++ {
+		if board[i][i]%2 == 1 {
+			cell := validator.Cell{Row: i, Col: i}
+			conflicts = append(conflicts, validator.Conflict{Unit: "even diagonal", First: cell, Second: cell, Rule: "odd digit"})
+		}
+	}
+	return conflicts
+}
+
+// String describes the rule
+func (evenDiagonal) String() string {
+	return "the main diagonal holds even digits"
+}
+
+// TestClassicConstraints verifies that the classic rules behave as IsValid
+// and ValidateBoard
+func TestClassicConstraints(t *testing.T) {
+	board := mustParse("53..7.... 6..195... .98....6. 8...6...3 4..8.3..1 7...2...6 .6....28. ...419..5 ....8..79")
+	rules := validator.Classic()
+	if len(rules) != 3 || rules[0].String() != "each row holds 1-9 once" {
+		t.Fatalf("Classic() = %v, expected rows, columns and boxes", rules)
+	}
+
+	// (0, 2) sees 5, 3, 7 in its row, 8 in its column and 6, 9 in its box
+	var candidates uint16 = validator.AllDigits
+	for _, rule := range rules {
+		candidates &= rule.Candidates(&board, 0, 2)
+	}
+	var expected uint16 = 1<<1 | 1<<2 | 1<<4
+	if candidates != expected {
+		t.Errorf("Classic() candidates of (0, 2) = %b, expected %b", candidates, expected)
+	}
+	for num := 1; num <= 9; num++ {
+		allowed := true
+		for _, rule := range rules {
+			allowed = allowed && rule.Check(&board, 0, 2, num)
+		}
+		if allowed != validator.IsValid(&board, 0, 2, num) {
+			t.Errorf("Classic() Check(0, 2, %d) = %v, expected IsValid() to agree", num, allowed)
+		}
+	}
+
+	board[0][2] = 5
+	var conflicts []validator.Conflict
+	for _, rule := range rules {
+		conflicts = append(conflicts, rule.Conflicts(&board)...)
+	}
+	expectedConflicts := validator.ValidateBoard(&board)
+	if len(conflicts) != 2 || len(expectedConflicts) != 2 || conflicts[0] != expectedConflicts[0] || conflicts[1] != expectedConflicts[1] {
+		t.Errorf("Classic() conflicts = %v, expected %v", conflicts, expectedConflicts)
+	}
+
+	// The classic rules handed out are copies, so changing them changes
+	// nothing for later callers
+	var classic *validator.Variant
+	classic.Rules()[0] = evenDiagonal{}
+	empty := utils.NewBoard()
+	if !classic.IsValid(&empty, 0, 0, 1) {
+		t.Errorf("IsValid(0, 0, 1) = false after changing Rules(), expected the classic rules")
+	}
+}
+
+// TestNewConstraintVariant verifies that a caller's constraint joins the
+// classic rules in IsValid, ValidateVariant and the Tracker
+func TestNewConstraintVariant(t *testing.T) {
+	variant := validator.Combine(validator.NewConstraintVariant("even-diagonal", evenDiagonal{}), validator.Windows())
+	if rules := variant.Rules(); len(rules) != 5 || rules[4].String() != "the main diagonal holds even digits" {
+		t.Errorf("Rules() = %v, expected the classic rules, windows and the even diagonal", rules)
+	}
+	if variant.ExactCover() {
+		t.Errorf("ExactCover() = true, expected false with a caller constraint")
+	}
+
+	board := utils.NewBoard()
+	tracker := validator.NewVariantTracker(&board, variant)
+	var even uint16 = 1<<2 | 1<<4 | 1<<6 | 1<<8
+	if mask := tracker.Candidates(4, 4); mask != even {
+		t.Errorf("Candidates(4, 4) = %b, expected %b", mask, even)
+	}
+	if variant.IsValid(&board, 3, 3, 5) || !variant.IsValid(&board, 3, 4, 5) {
+		t.Errorf("IsValid() allows 5 on the diagonal or refuses it beside")
+	}
+
+	board[3][3] = 5
+	conflicts := validator.ValidateVariant(&board, variant)
+	if len(conflicts) != 1 || conflicts[0].String() != "odd digit in even diagonal 0 at (3, 3) and (3, 3)" {
+		t.Errorf("ValidateVariant() = %v, expected one odd digit conflict", conflicts)
+	}
+
+	validator.RegisterVariant("even-diagonal", func() *validator.Variant {
+		return validator.NewConstraintVariant("even-diagonal", evenDiagonal{})
+	})
+	parsed, err := validator.ParseVariants("x,even-diagonal")
+	if err != nil || len(parsed.Constraints) != 1 {
+		t.Fatalf("ParseVariants(\"x,even-diagonal\") = %v, %v, expected the registered constraint", parsed, err)
+	}
+
+	// The registered constraint applies alongside the diagonals
+	empty := utils.NewBoard()
+	if parsed.IsValid(&empty, 3, 3, 5) || !parsed.IsValid(&empty, 3, 3, 4) {
+		t.Errorf("IsValid(3, 3) of the registered variant allows 5 or refuses 4 on the diagonal")
+	}
+	if mask := validator.NewVariantTracker(&empty, parsed).Candidates(2, 2); mask != even {
+		t.Errorf("Candidates(2, 2) of the registered variant = %b, expected %b", mask, even)
+	}
+	board[3][3], board[4][4] = 0, 7
+	conflicts = validator.ValidateVariant(&board, parsed)
+	if len(conflicts) != 1 || conflicts[0].First != (validator.Cell{Row: 4, Col: 4}) {
+		t.Errorf("ValidateVariant() of the registered variant = %v, expected the odd digit at (4, 4)", conflicts)
+	}
+}
+
+// TestRegisterConstraint verifies that a registered constraint joins the
+// classic rules of IsValid, the Tracker and ValidateVariant, for classic
+// boards and variants alike, until it is removed
+func TestRegisterConstraint(t *testing.T) {
+	validator.RegisterConstraint("even-diagonal", evenDiagonal{})
+	t.Cleanup(func() { validator.RegisterConstraint("even-diagonal", nil) })
+
+	empty := utils.NewBoard()
+	if validator.IsValid(&empty, 3, 3, 5) || !validator.IsValid(&empty, 3, 3, 4) || !validator.IsValid(&empty, 3, 4, 5) {
+		t.Errorf("IsValid() with a registered even diagonal allows 5 at (3, 3) or refuses 4 there or 5 at (3, 4)")
+	}
+	if validator.Windows().IsValid(&empty, 3, 3, 5) {
+		t.Errorf("Windows().IsValid(3, 3, 5) = true, expected the registered rule to apply to variants")
+	}
+
+	var even uint16 = 1<<2 | 1<<4 | 1<<6 | 1<<8
+	if mask := validator.NewTracker(&empty).Candidates(2, 2); mask != even {
+		t.Errorf("Candidates(2, 2) = %b, expected %b", mask, even)
+	}
+
+	board := empty
+	board[4][4] = 7
+	conflicts := validator.ValidateVariant(&board, nil)
+	if len(conflicts) != 1 || conflicts[0].First != (validator.Cell{Row: 4, Col: 4}) {
+		t.Errorf("ValidateVariant() = %v, expected the odd digit at (4, 4)", conflicts)
+	}
+
+	validator.RegisterConstraint("even-diagonal", nil)
+	if !validator.IsValid(&empty, 3, 3, 5) || len(validator.ValidateVariant(&board, nil)) > 0 {
+		t.Errorf("IsValid() and ValidateVariant() still apply the even diagonal after it was removed")
+	}
+}
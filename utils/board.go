@@ -1,6 +1,10 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+)
 
 // Board represents a 9x9 Sudoku grid
 // 0 = empty cell, 1-9 = filled cell
@@ -24,16 +28,21 @@ func CharToInt(c byte) int {
 // Each row on a new line, numbers seperated by spaces
 // Final empty line at the end
 func PrintBoard(board *Board) {
+	FprintBoard(os.Stdout, board)
+}
+
+// FprintBoard is PrintBoard writing to w
+func FprintBoard(w io.Writer, board *Board) {
 	for row := 0; row < 9; row++ {
 		for col := 0; col < 9; col++ {
-			fmt.Print(board[row][col])
+			fmt.Fprint(w, board[row][col])
 			if col < 8 {
-				fmt.Print(" ")
+				fmt.Fprint(w, " ")
 			}
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // FindEmptyCell returns the coordinates of the next empty cell (value = 0)
@@ -48,3 +57,25 @@ func FindEmptyCell(board *Board) (int, int) {
 	}
 	return -1, -1
 }
+
+// IntToChar converts an integer (0-9) back to its character ('.' for 0)
+func IntToChar(n int) byte {
+	if n == 0 {
+		return '.'
+	}
+	return byte('0' + n)
+}
+
+// FormatRows converts the board into nine row strings ('.' for empty cells)
+// This is the same format parser.ParseArgs reads
+func FormatRows(board *Board) []string {
+	rows := make([]string, 9)
+	for row := 0; row < 9; row++ {
+		line := make([]byte, 9)
+		for col := 0; col < 9; col++ {
+			line[col] = IntToChar(board[row][col])
+		}
+		rows[row] = string(line)
+	}
+	return rows
+}
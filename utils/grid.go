@@ -0,0 +1,201 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Symbols is the default digit alphabet: digits 1-9, then letters
+// A 16x16 grid uses 1-9 and A-G, a 25x25 grid 1-9 and A-P
+const Symbols = "123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// MaxSize is the largest grid side the default alphabet can write
+const MaxSize = len(Symbols)
+
+// ErrInvalidShape is returned for box dimensions no grid can have
+var ErrInvalidShape = errors.New("Error: Invalid grid size")
+
+// Shape describes a grid by the dimensions of its boxes
+// A grid is BoxRows*BoxCols cells on each side and holds that many digits
+type Shape struct {
+	BoxRows int // Height of a box
+	BoxCols int // Width of a box
+}
+
+// Classic is the standard 9x9 grid with 3x3 boxes
+var Classic = Shape{BoxRows: 3, BoxCols: 3}
+
+// Size returns the number of cells on each side, which is also the
+// number of digits
+func (s Shape) Size() int {
+	return s.BoxRows * s.BoxCols
+}
+
+// Box returns the index of the box holding (row, col), numbered in
+// reading order from 0
+func (s Shape) Box(row, col int) int {
+	return (row/s.BoxRows)*s.BoxRows + col/s.BoxCols
+}
+
+// String formats the shape as "9x9 (3x3 boxes)"
+func (s Shape) String() string {
+	return fmt.Sprintf("%dx%d (%dx%d boxes)", s.Size(), s.Size(), s.BoxRows, s.BoxCols)
+}
+
+// ParseShape reads a grid size such as "16", or box dimensions such as "2x3"
+// A plain size picks the squarest boxes, wider than tall: 6 gives 2x3 boxes
+func ParseShape(text string) (Shape, error) {
+	if rows, cols, ok := strings.Cut(text, "x"); ok {
+		boxRows, err1 := strconv.Atoi(rows)
+		boxCols, err2 := strconv.Atoi(cols)
+		shape := Shape{BoxRows: boxRows, BoxCols: boxCols}
+		if err1 != nil || err2 != nil || !shape.valid() {
+			return Shape{}, fmt.Errorf("%w %q", ErrInvalidShape, text)
+		}
+		return shape, nil
+	}
+
+	size, err := strconv.Atoi(text)
+	if err != nil {
+		return Shape{}, fmt.Errorf("%w %q", ErrInvalidShape, text)
+	}
+
+	// Boxes as close to square as possible: the tallest height up to the
+	// square root that divides the size
+	for boxRows := intSqrt(size); boxRows >= 2; boxRows-- {
+		if size%boxRows == 0 {
+			shape := Shape{BoxRows: boxRows, BoxCols: size / boxRows}
+			if shape.valid() {
+				return shape, nil
+			}
+			break
+		}
+	}
+	return Shape{}, fmt.Errorf("%w %q", ErrInvalidShape, text)
+}
+
+// valid checks that the shape has at least two digits and few enough to write
+func (s Shape) valid() bool {
+	return s.BoxRows >= 1 && s.BoxCols >= 1 && s.Size() >= 2 && s.Size() <= MaxSize
+}
+
+// intSqrt returns the largest n with n*n <= x
+func intSqrt(x int) int {
+	n := 0
+	for (n+1)*(n+1) <= x {
+		n++
+	}
+	return n
+}
+
+// Grid is a sudoku board of any shape
+// Cells hold 0 for empty, or a digit 1..Size() written with Alphabet
+type Grid struct {
+	Shape
+	Alphabet string  // Symbol of each digit: Alphabet[0] is digit 1
+	Cells    [][]int // Indexed [row][col]
+}
+
+// NewGrid creates an empty grid of the given shape using the default alphabet
+func NewGrid(shape Shape) *Grid {
+	size := shape.Size()
+	cells := make([][]int, size)
+	for row := range cells {
+		cells[row] = make([]int, size)
+	}
+	return &Grid{Shape: shape, Alphabet: Symbols[:size], Cells: cells}
+}
+
+// GridFromBoard copies a classic board into a 9x9 grid
+func GridFromBoard(board *Board) *Grid {
+	g := NewGrid(Classic)
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			g.Cells[row][col] = board[row][col]
+		}
+	}
+	return g
+}
+
+// Board copies a 9x9 grid back into a classic board
+// Returns false if the grid has any other shape
+func (g *Grid) Board() (Board, bool) {
+	var board Board
+	if g.Size() != 9 || g.BoxRows != 3 {
+		return board, false
+	}
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			board[row][col] = g.Cells[row][col]
+		}
+	}
+	return board, true
+}
+
+// Clone returns a deep copy of the grid
+func (g *Grid) Clone() *Grid {
+	clone := *g
+	clone.Cells = make([][]int, len(g.Cells))
+	for row := range g.Cells {
+		clone.Cells[row] = append([]int(nil), g.Cells[row]...)
+	}
+	return &clone
+}
+
+// Symbol returns the character for a cell value ('.' for 0)
+func (g *Grid) Symbol(value int) byte {
+	if value == 0 {
+		return '.'
+	}
+	return g.Alphabet[value-1]
+}
+
+// Value converts a character to a cell value ('.' is 0)
+// Returns false if the character is not in the alphabet
+func (g *Grid) Value(c byte) (int, bool) {
+	if c == '.' {
+		return 0, true
+	}
+	index := strings.IndexByte(g.Alphabet, c)
+	if index < 0 {
+		return 0, false
+	}
+	return index + 1, true
+}
+
+// FormatRows converts the grid into one string per row ('.' for empty cells)
+func (g *Grid) FormatRows() []string {
+	rows := make([]string, g.Size())
+	for row := range rows {
+		line := make([]byte, g.Size())
+		for col := range line {
+			line[col] = g.Symbol(g.Cells[row][col])
+		}
+		rows[row] = string(line)
+	}
+	return rows
+}
+
+// PrintGrid prints the grid like PrintBoard: each row on a new line,
+// symbols separated by spaces, with a final empty line
+func PrintGrid(g *Grid) {
+	FprintGrid(os.Stdout, g)
+}
+
+// FprintGrid is PrintGrid writing to w
+func FprintGrid(w io.Writer, g *Grid) {
+	for row := 0; row < g.Size(); row++ {
+		for col := 0; col < g.Size(); col++ {
+			fmt.Fprint(w, string(g.Symbol(g.Cells[row][col])))
+			if col < g.Size()-1 {
+				fmt.Fprint(w, " ")
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+}
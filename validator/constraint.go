@@ -0,0 +1,320 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"sudoku/utils"
+)
+
+// Constraint is one rule every solution must follow
+// The classic rules are the Rows, Columns and Boxes constraints; variants
+// add their own, and callers can supply more with NewConstraintVariant, or
+// for every puzzle with RegisterConstraint
+// IsValid, Variant.IsValid, ValidateVariant and the solvers (through the
+// Tracker) consult every constraint, so new rules need no solver changes
+type Constraint interface {
+	// Check reports whether num may be placed at the empty cell (row, col)
+	Check(board *utils.Board, row, col, num int) bool
+
+	// Candidates returns the mask of digits (row, col) may hold given the
+	// other cells of the board, which the solvers use to propagate the rule
+	Candidates(board *utils.Board, row, col int) uint16
+
+	// Conflicts reports every place the filled cells already break the rule
+	Conflicts(board *utils.Board) []Conflict
+
+	// String describes the rule, as in "each row holds 1-9 once"
+	String() string
+}
+
+// The classic rules must keep satisfying Constraint
+var (
+	_ Constraint = Rows{}
+	_ Constraint = Columns{}
+	_ Constraint = Boxes{}
+)
+
+// classic holds the three classic rules, shared by every nil variant
+var classic = []Constraint{Rows{}, Columns{}, Boxes{}}
+
+// Classic returns the three rules of classic sudoku: rows, columns and 3x3 boxes
+func Classic() []Constraint {
+	return append([]Constraint(nil), classic...)
+}
+
+// registered maps the names given to RegisterConstraint to their rules,
+// and registeredRules lists the same rules ordered by name
+var (
+	registered      = map[string]Constraint{}
+	registeredRules []Constraint
+)
+
+// RegisterConstraint adds a rule to every puzzle, classic or variant, so
+// IsValid, Variant.IsValid, ValidateVariant and the backtracking backends
+// consult it after the rules they already have
+// Registering the same name twice replaces the earlier rule; registering
+// nil removes it
+func RegisterConstraint(name string, c Constraint) {
+	if c == nil {
+		delete(registered, name)
+	} else {
+		registered[name] = c
+	}
+
+	names := make([]string, 0, len(registered))
+	for name := range registered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	registeredRules = nil
+	for _, name := range names {
+		registeredRules = append(registeredRules, registered[name])
+	}
+}
+
+// Rows is the rule that no row repeats a digit
+type Rows struct{}
+
+// Check reports whether num is missing from the row
+func (Rows) Check(board *utils.Board, row, col, num int) bool {
+	return isRowValid(board, row, num)
+}
+
+// Candidates returns the digits missing from the rest of the row
+func (Rows) Candidates(board *utils.Board, row, col int) uint16 {
+	return AllDigits &^ usedDigits(board, RowCells(row), Cell{row, col})
+}
+
+// Conflicts reports every repeated digit, row by row
+func (Rows) Conflicts(board *utils.Board) []Conflict {
+	var conflicts []Conflict
+	grid := utils.GridFromBoard(board)
+	for row := 0; row < 9; row++ {
+		conflicts = append(conflicts, findDuplicates(grid, UnitRow, row, RowCells(row))...)
+	}
+	return conflicts
+}
+
+// String describes the rule
+func (Rows) String() string {
+	return "each row holds 1-9 once"
+}
+
+// Columns is the rule that no column repeats a digit
+type Columns struct{}
+
+// Check reports whether num is missing from the column
+func (Columns) Check(board *utils.Board, row, col, num int) bool {
+	return isColValid(board, col, num)
+}
+
+// Candidates returns the digits missing from the rest of the column
+func (Columns) Candidates(board *utils.Board, row, col int) uint16 {
+	return AllDigits &^ usedDigits(board, ColCells(col), Cell{row, col})
+}
+
+// Conflicts reports every repeated digit, column by column
+func (Columns) Conflicts(board *utils.Board) []Conflict {
+	var conflicts []Conflict
+	grid := utils.GridFromBoard(board)
+	for col := 0; col < 9; col++ {
+		conflicts = append(conflicts, findDuplicates(grid, UnitColumn, col, ColCells(col))...)
+	}
+	return conflicts
+}
+
+// String describes the rule
+func (Columns) String() string {
+	return "each column holds 1-9 once"
+}
+
+// Boxes is the rule that no box repeats a digit
+// The zero value uses the 3x3 boxes; Variant.Rules gives jigsaw variants
+// their own regions
+type Boxes struct {
+	variant *Variant
+}
+
+// Check reports whether num is missing from the box
+func (b Boxes) Check(board *utils.Board, row, col, num int) bool {
+	if b.variant == nil {
+		return isBoxValid(board, row, col, num)
+	}
+	return usedDigits(board, b.variant.BoxCells(b.variant.BoxIndex(row, col)), Cell{-1, -1})&(1<<num) == 0
+}
+
+// Candidates returns the digits missing from the rest of the box
+func (b Boxes) Candidates(board *utils.Board, row, col int) uint16 {
+	return AllDigits &^ usedDigits(board, b.variant.BoxCells(b.variant.BoxIndex(row, col)), Cell{row, col})
+}
+
+// Conflicts reports every repeated digit, box by box
+func (b Boxes) Conflicts(board *utils.Board) []Conflict {
+	var conflicts []Conflict
+	grid := utils.GridFromBoard(board)
+	for box := 0; box < 9; box++ {
+		conflicts = append(conflicts, findDuplicates(grid, UnitBox, box, b.variant.BoxCells(box))...)
+	}
+	return conflicts
+}
+
+// String describes the rule
+func (b Boxes) String() string {
+	if b.variant.HasIrregularBoxes() {
+		return "each jigsaw region holds 1-9 once"
+	}
+	return "each 3x3 box holds 1-9 once"
+}
+
+// usedDigits returns the mask of digits filled in cells, leaving out skip
+func usedDigits(board *utils.Board, cells []Cell, skip Cell) uint16 {
+	var used uint16
+	for _, cell := range cells {
+		if cell != skip {
+			used |= 1 << board[cell.Row][cell.Col]
+		}
+	}
+	return used &^ 1 // Bit 0 marks empty cells
+}
+
+// regionRule enforces the extra regions of a variant: no repeats, and the
+// target sum of killer cages
+type regionRule struct{ v *Variant }
+
+// Check reports whether num fits every region holding the cell
+func (r regionRule) Check(board *utils.Board, row, col, num int) bool {
+	return r.Candidates(board, row, col)&(1<<num) != 0
+}
+
+// Candidates returns the digits missing from the cell's regions that keep
+// their sums reachable
+func (r regionRule) Candidates(board *utils.Board, row, col int) uint16 {
+	allowed := AllDigits
+	for _, index := range r.v.RegionsOf(row, col) {
+		region := r.v.Regions[index]
+		used, total, left := regionSum(board, region, Cell{row, col})
+		allowed &^= used
+		if region.Sum > 0 {
+			allowed &= sumCandidates(used, region.Sum-total, left)
+		}
+	}
+	return allowed
+}
+
+// Conflicts reports repeats in every region, then sums out of reach
+func (r regionRule) Conflicts(board *utils.Board) []Conflict {
+	var conflicts []Conflict
+	grid := utils.GridFromBoard(board)
+	for _, region := range r.v.Regions {
+		conflicts = append(conflicts, findDuplicates(grid, region.Unit, region.Index, region.Cells)...)
+	}
+	for _, region := range r.v.Regions {
+		if region.Sum == 0 {
+			continue
+		}
+		if conflict, found := sumConflict(board, region); found {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts
+}
+
+// String describes the rule
+func (r regionRule) String() string {
+	return fmt.Sprintf("%d extra regions", len(r.v.Regions))
+}
+
+// pairRule enforces the related cell pairs of a variant
+type pairRule struct{ v *Variant }
+
+// Check reports whether num suits the filled partners of the cell
+func (p pairRule) Check(board *utils.Board, row, col, num int) bool {
+	return p.v.pairsAllow(board, row, col)&(1<<num) != 0
+}
+
+// Candidates returns the digits the filled partners of the cell allow
+func (p pairRule) Candidates(board *utils.Board, row, col int) uint16 {
+	return p.v.pairsAllow(board, row, col)
+}
+
+// Conflicts reports every pair whose filled cells break its relation
+func (p pairRule) Conflicts(board *utils.Board) []Conflict {
+	return p.v.pairConflicts(board)
+}
+
+// String describes the rule
+func (p pairRule) String() string {
+	return fmt.Sprintf("%d related cell pairs", len(p.v.Pairs))
+}
+
+// lineRule enforces the arrows and sandwich sums of a variant
+type lineRule struct{ v *Variant }
+
+// Check reports whether num leaves the cell's arrows and sandwiches possible
+func (l lineRule) Check(board *utils.Board, row, col, num int) bool {
+	return l.v.linesAllow(board, row, col)&(1<<num) != 0
+}
+
+// Candidates returns the digits that leave the cell's arrows and sandwiches
+// possible to complete
+func (l lineRule) Candidates(board *utils.Board, row, col int) uint16 {
+	return l.v.linesAllow(board, row, col)
+}
+
+// Conflicts reports every arrow and sandwich that can no longer be completed
+func (l lineRule) Conflicts(board *utils.Board) []Conflict {
+	return l.v.lineConflicts(board)
+}
+
+// String describes the rule
+func (l lineRule) String() string {
+	return fmt.Sprintf("%d arrows and %d sandwich sums", len(l.v.Arrows), len(l.v.Sandwiches))
+}
+
+// NewConstraintVariant creates a variant from rules supplied by the caller
+// Combine it with other variants to add the rules to them
+func NewConstraintVariant(name string, constraints ...Constraint) *Variant {
+	v := &Variant{Name: name, Constraints: constraints}
+	v.index()
+	return v
+}
+
+// Rules lists every constraint of the variant in the order conflicts are
+// reported: rows, columns, boxes, extra regions, pairs, arrows and
+// sandwiches, then the caller's constraints and the registered ones
+func (v *Variant) Rules() []Constraint {
+	if v == nil {
+		return append(Classic(), registeredRules...)
+	}
+	rules := []Constraint{Rows{}, Columns{}, Boxes{v}}
+	if len(v.Regions) > 0 {
+		rules = append(rules, regionRule{v})
+	}
+	return append(rules, v.searchRules()...)
+}
+
+// searchRules lists the constraints a Tracker cannot keep as digit masks,
+// which it asks for the candidates of every cell
+func (v *Variant) searchRules() []Constraint {
+	if v == nil {
+		return registeredRules
+	}
+	var rules []Constraint
+	if len(v.Pairs) > 0 {
+		rules = append(rules, pairRule{v})
+	}
+	if v.HasLineClues() {
+		rules = append(rules, lineRule{v})
+	}
+	rules = append(rules, v.Constraints...)
+	return append(rules, registeredRules...)
+}
+
+// checks is Rules without copying the classic rules when nothing is
+// registered, as IsValid runs once per trial digit
+func (v *Variant) checks() []Constraint {
+	if v == nil && len(registeredRules) == 0 {
+		return classic
+	}
+	return v.Rules()
+}
@@ -0,0 +1,150 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"sudoku/utils"
+)
+
+// UnitCage is the kind of the cages of killer sudoku
+const UnitCage Unit = "cage"
+
+// ErrInvalidCages is returned for cages no killer puzzle can have
+var ErrInvalidCages = errors.New("Error: Invalid cages")
+
+// Cage is a group of cells whose digits, all different, add up to Sum
+type Cage struct {
+	Sum   int
+	Cells []Cell
+}
+
+// Killer creates the killer sudoku variant from its cages
+// Returns ErrInvalidCages if a cell is off the board or in two cages, or a
+// cage's sum cannot be made from that many different digits
+func Killer(cages []Cage) (*Variant, error) {
+	var owner [9][9]int // Cage number + 1 of every cell, 0 if none
+	regions := make([]Region, len(cages))
+	for i, cage := range cages {
+		if len(cage.Cells) == 0 || len(cage.Cells) > 9 {
+			return nil, fmt.Errorf("%w: cage %d has %d cells", ErrInvalidCages, i, len(cage.Cells))
+		}
+		for _, cell := range cage.Cells {
+			if cell.Row < 0 || cell.Row > 8 || cell.Col < 0 || cell.Col > 8 {
+				return nil, fmt.Errorf("%w: cage %d has (%d, %d) off the board", ErrInvalidCages, i, cell.Row, cell.Col)
+			}
+			if owner[cell.Row][cell.Col] != 0 {
+				return nil, fmt.Errorf("%w: (%d, %d) is in cages %d and %d", ErrInvalidCages, cell.Row, cell.Col, owner[cell.Row][cell.Col]-1, i)
+			}
+			owner[cell.Row][cell.Col] = i + 1
+		}
+		if !sumReachable(0, cage.Sum, len(cage.Cells)) {
+			return nil, fmt.Errorf("%w: cage %d cannot sum to %d with %d digits", ErrInvalidCages, i, cage.Sum, len(cage.Cells))
+		}
+		regions[i] = Region{Unit: UnitCage, Index: i, Cells: cage.Cells, Sum: cage.Sum}
+	}
+	return NewVariant("killer", regions), nil
+}
+
+// HasSums checks if any region of the variant has a target sum
+func (v *Variant) HasSums() bool {
+	if v == nil {
+		return false
+	}
+	for _, region := range v.Regions {
+		if region.Sum > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// regionSum adds up the digits placed in a region, skipping skip
+// Returns the mask of those digits, their total and how many cells are empty
+func regionSum(board *utils.Board, region Region, skip Cell) (uint16, int, int) {
+	var used uint16
+	total, left := 0, 0
+	for _, cell := range region.Cells {
+		num := board[cell.Row][cell.Col]
+		if cell == skip || num == 0 {
+			left++
+			continue
+		}
+		used |= 1 << num
+		total += num
+	}
+	return used, total, left
+}
+
+// sumConflict checks the givens of a region with a target sum
+// Returns a conflict between its first and last filled cells if the empty
+// cells can no longer bring the total to the target
+func sumConflict(board *utils.Board, region Region) (Conflict, bool) {
+	used, total, left := regionSum(board, region, Cell{-1, -1})
+	if sumReachable(used, region.Sum-total, left) {
+		return Conflict{}, false
+	}
+
+	var filled []Cell
+	for _, cell := range region.Cells {
+		if board[cell.Row][cell.Col] != 0 {
+			filled = append(filled, cell)
+		}
+	}
+	if len(filled) == 0 {
+		return Conflict{}, false // Nothing given yet to blame
+	}
+	rule := fmt.Sprintf("sum %d cannot reach %d", total, region.Sum)
+	if left == 0 {
+		rule = fmt.Sprintf("sum %d instead of %d", total, region.Sum)
+	}
+	return Conflict{
+		Unit:   region.Unit,
+		Index:  region.Index,
+		First:  filled[0],
+		Second: filled[len(filled)-1],
+		Rule:   rule,
+	}, true
+}
+
+// sumCandidates returns the digits that may go in the left empty cells of a
+// region needing remaining more, given the mask of digits it already holds
+// Only digits of some set of left different unused digits adding up to
+// remaining are kept
+func sumCandidates(used uint16, remaining, left int) uint16 {
+	var mask uint16
+	if left < 0 || left > 9 || remaining < 0 || remaining > 45 {
+		return 0
+	}
+	for _, combination := range combinations[left][remaining] {
+		if combination&used == 0 {
+			mask |= combination
+		}
+	}
+	return mask
+}
+
+// sumReachable checks if left different digits, none in used, can add up
+// to remaining
+func sumReachable(used uint16, remaining, left int) bool {
+	return left == 0 && remaining == 0 || sumCandidates(used, remaining, left) != 0
+}
+
+// combinations lists, by count and total, every set of different digits as
+// a mask (bit n for digit n)
+var combinations = buildCombinations()
+
+// buildCombinations sorts all 512 subsets of 1-9 by their size and sum
+func buildCombinations() [10][46][]uint16 {
+	var result [10][46][]uint16
+	for mask := uint16(0); mask <= AllDigits; mask += 2 {
+		total := 0
+		for num := 1; num <= 9; num++ {
+			if mask&(1<<num) != 0 {
+				total += num
+			}
+		}
+		count := CountCandidates(mask)
+		result[count][total] = append(result[count][total], mask)
+	}
+	return result
+}
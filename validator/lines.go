@@ -0,0 +1,348 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sudoku/utils"
+)
+
+// Kinds of line clues
+const (
+	UnitThermo   Unit = "thermo"   // Digits rise from the bulb
+	UnitArrow    Unit = "arrow"    // The circle is the sum of the arrow
+	UnitSandwich Unit = "sandwich" // Sum between the 1 and the 9 of a row or column
+)
+
+// ErrInvalidClues is returned for thermos, arrows and sandwiches that do not
+// fit on the board
+var ErrInvalidClues = errors.New("Error: Invalid clues")
+
+// Arrow is a circled cell holding the sum of the cells along its arrow
+// Digits on the arrow may repeat where no other rule forbids it
+type Arrow struct {
+	Circle Cell
+	Cells  []Cell
+}
+
+// Sandwich is the sum of the digits between the 1 and the 9 of a row or column
+type Sandwich struct {
+	Column bool // Clue of column Index instead of row Index
+	Index  int  // Row or column, 0-8
+	Sum    int
+}
+
+// cells lists the cells of the sandwich's row or column in order
+func (s Sandwich) cells() []Cell {
+	if s.Column {
+		return ColCells(s.Index)
+	}
+	return RowCells(s.Index)
+}
+
+// Clues lists the thermometers, arrows and sandwich sums of a puzzle
+// Each thermometer lists its cells from the bulb to the tip
+type Clues struct {
+	Thermos    [][]Cell
+	Arrows     []Arrow
+	Sandwiches []Sandwich
+}
+
+// Variant creates the variant enforcing the clues, named after the kinds
+// of clue it has
+// Returns ErrInvalidClues if a cell is off the board, a thermometer or arrow
+// steps between cells that do not touch or repeats a cell, or a sandwich
+// has an impossible sum or shares its row or column with another
+func (c Clues) Variant() (*Variant, error) {
+	v := &Variant{}
+	var names []string
+
+	for i, thermo := range c.Thermos {
+		if err := checkPath(thermo, 2); err != nil {
+			return nil, fmt.Errorf("%w: thermo %d %v", ErrInvalidClues, i, err)
+		}
+		v.Pairs = append(v.Pairs, thermoPairs(i, thermo)...)
+	}
+	if len(c.Thermos) > 0 {
+		names = append(names, "thermo")
+	}
+
+	for i, arrow := range c.Arrows {
+		if err := checkPath(append([]Cell{arrow.Circle}, arrow.Cells...), 2); err != nil {
+			return nil, fmt.Errorf("%w: arrow %d %v", ErrInvalidClues, i, err)
+		}
+		if len(arrow.Cells) > 9 {
+			return nil, fmt.Errorf("%w: arrow %d is too long to add up to 9 or less", ErrInvalidClues, i)
+		}
+		v.Arrows = append(v.Arrows, arrow)
+	}
+	if len(c.Arrows) > 0 {
+		names = append(names, "arrow")
+	}
+
+	seen := map[Sandwich]bool{}
+	for i, sandwich := range c.Sandwiches {
+		line := Sandwich{Column: sandwich.Column, Index: sandwich.Index}
+		switch {
+		case sandwich.Index < 0 || sandwich.Index > 8:
+			return nil, fmt.Errorf("%w: sandwich %d is off the board", ErrInvalidClues, i)
+		case sandwich.Sum < 0 || sandwich.Sum > 35:
+			return nil, fmt.Errorf("%w: sandwich %d sum %d is not 0-35", ErrInvalidClues, i, sandwich.Sum)
+		case seen[line]:
+			return nil, fmt.Errorf("%w: sandwich %d repeats an earlier row or column", ErrInvalidClues, i)
+		}
+		seen[line] = true
+		v.Sandwiches = append(v.Sandwiches, sandwich)
+	}
+	if len(c.Sandwiches) > 0 {
+		names = append(names, "sandwich")
+	}
+
+	v.Name = strings.Join(names, ",")
+	v.index()
+	return v, nil
+}
+
+// checkPath checks that a path has at least min distinct cells on the
+// board, each touching the one before (diagonals included)
+func checkPath(path []Cell, min int) error {
+	if len(path) < min {
+		return fmt.Errorf("has %d cells, expected at least %d", len(path), min)
+	}
+	for i, cell := range path {
+		if !onBoard(cell) {
+			return fmt.Errorf("has (%d, %d) off the board", cell.Row, cell.Col)
+		}
+		if containsCell(path[:i], cell) {
+			return fmt.Errorf("repeats (%d, %d)", cell.Row, cell.Col)
+		}
+		if i > 0 && !touching(path[i-1], cell) {
+			return fmt.Errorf("jumps from (%d, %d) to (%d, %d)", path[i-1].Row, path[i-1].Col, cell.Row, cell.Col)
+		}
+	}
+	return nil
+}
+
+// touching checks if two different cells share a side or a corner
+func touching(a, b Cell) bool {
+	dr, dc := a.Row-b.Row, a.Col-b.Col
+	return a != b && dr >= -1 && dr <= 1 && dc >= -1 && dc <= 1
+}
+
+// rises holds, for each distance along a thermometer, the relation that the
+// later digit is at least that much higher
+var rises = buildRises()
+
+// buildRises creates the thermometer relations for distances 1-8
+func buildRises() [9]*Relation {
+	var result [9]*Relation
+	for gap := 1; gap <= 8; gap++ {
+		result[gap] = NewRelation(UnitThermo, func(a, b int) bool { return b-a >= gap })
+		result[gap].word = "then"
+	}
+	return result
+}
+
+// thermoPairs relates every two cells of a thermometer: a cell further from
+// the bulb must be higher by at least the distance between them
+func thermoPairs(index int, thermo []Cell) []Pair {
+	var pairs []Pair
+	for i := range thermo {
+		for j := i + 1; j < len(thermo) && j-i <= 8; j++ {
+			pairs = append(pairs, Pair{Relation: rises[j-i], Index: index, A: thermo[i], B: thermo[j]})
+		}
+	}
+	return pairs
+}
+
+// HasLineClues checks if the variant has arrows or sandwiches
+// Thermos are stored as pairs
+func (v *Variant) HasLineClues() bool {
+	return v != nil && (len(v.Arrows) > 0 || len(v.Sandwiches) > 0)
+}
+
+// ArrowsOf returns the indexes (into Arrows) of the arrows holding (row, col)
+func (v *Variant) ArrowsOf(row, col int) []int {
+	if v == nil {
+		return nil
+	}
+	return v.arrowsOf[row][col]
+}
+
+// SandwichesOf returns the indexes (into Sandwiches) of the sandwich sums
+// covering (row, col)
+func (v *Variant) SandwichesOf(row, col int) []int {
+	if v == nil {
+		return nil
+	}
+	return v.sandwichesOf[row][col]
+}
+
+// linesAllow returns the digits (row, col) may hold given the arrows and
+// sandwiches it is part of and the other cells on them
+func (v *Variant) linesAllow(board *utils.Board, row, col int) uint16 {
+	allowed := AllDigits
+	for _, index := range v.ArrowsOf(row, col) {
+		allowed &= arrowAllow(board, v.Arrows[index], Cell{row, col})
+	}
+	for _, index := range v.SandwichesOf(row, col) {
+		allowed &= sandwichAllow(board, v.Sandwiches[index], Cell{row, col})
+	}
+	return allowed
+}
+
+// lineConflicts reports every arrow and sandwich the givens already break
+func (v *Variant) lineConflicts(board *utils.Board) []Conflict {
+	var conflicts []Conflict
+	for i, arrow := range v.Arrows {
+		if arrowAllow(board, arrow, Cell{-1, -1}) == 0 {
+			cells := append([]Cell{arrow.Circle}, arrow.Cells...)
+			conflicts = append(conflicts, lineConflict(board, UnitArrow, i, cells, "circle cannot be the sum"))
+		}
+	}
+	for i, sandwich := range v.Sandwiches {
+		if sandwichAllow(board, sandwich, Cell{-1, -1}) == 0 {
+			rule := fmt.Sprintf("sum %d cannot be made", sandwich.Sum)
+			conflicts = append(conflicts, lineConflict(board, UnitSandwich, i, sandwich.cells(), rule))
+		}
+	}
+	return conflicts
+}
+
+// lineConflict blames the first and last filled cells of a broken line
+func lineConflict(board *utils.Board, unit Unit, index int, cells []Cell, rule string) Conflict {
+	filled := []Cell{cells[0]} // Only if nothing is filled
+	for _, cell := range cells {
+		if board[cell.Row][cell.Col] != 0 {
+			filled = append(filled, cell)
+		}
+	}
+	if len(filled) > 1 {
+		filled = filled[1:]
+	}
+	return Conflict{
+		Unit:   unit,
+		Index:  index,
+		First:  filled[0],
+		Second: filled[len(filled)-1],
+		Rule:   rule,
+	}
+}
+
+// arrowAllow returns the digits cell may hold so the circle can still be
+// the sum of the arrow, treating cell as empty
+// For a cell off the arrow it returns AllDigits if the arrow can still be
+// completed, 0 otherwise
+func arrowAllow(board *utils.Board, arrow Arrow, cell Cell) uint16 {
+	// Add up the arrow, leaving out cell
+	sum, empty := 0, 0
+	onArrow := false
+	for _, c := range arrow.Cells {
+		switch {
+		case c == cell:
+			onArrow = true
+		case board[c.Row][c.Col] == 0:
+			empty++
+		default:
+			sum += board[c.Row][c.Col]
+		}
+	}
+	circle := board[arrow.Circle.Row][arrow.Circle.Col]
+	if arrow.Circle == cell {
+		circle = 0
+	}
+
+	// Totals the arrow can still reach, and the circle values it allows
+	low, high := sum+empty, sum+9*empty
+	var allowed uint16
+	for num := 1; num <= 9; num++ {
+		var fits bool
+		switch {
+		case arrow.Circle == cell:
+			fits = num >= low && num <= high
+		case onArrow && circle != 0:
+			fits = circle >= low+num && circle <= high+num
+		case onArrow:
+			fits = low+num <= 9
+		default:
+			fits = circle == 0 && low <= 9 || circle >= low && circle <= high
+		}
+		if fits {
+			allowed |= 1 << num
+		}
+	}
+	return allowed
+}
+
+// sandwichAllow returns the digits cell may hold so the digits between the
+// 1 and the 9 can still add up to the sum, treating cell as empty
+// For a cell off the line it returns AllDigits if the sum can still be
+// made, 0 otherwise
+func sandwichAllow(board *utils.Board, sandwich Sandwich, cell Cell) uint16 {
+	cells := sandwich.cells()
+	var values [9]int
+	var used uint16
+	at := -1
+	for i, c := range cells {
+		if c == cell {
+			at = i
+			continue
+		}
+		values[i] = board[c.Row][c.Col]
+		used |= 1 << values[i]
+	}
+	used &^= 1 // Bit 0 marks empty cells
+
+	// Try every position of the 1 and the 9 the filled cells leave open
+	var allowed uint16
+	for one := 0; one < 9; one++ {
+		for nine := 0; nine < 9; nine++ {
+			if one == nine || !fitsEnd(values, one, 1, used) || !fitsEnd(values, nine, 9, used) {
+				continue
+			}
+
+			// Add up the filled cells between the ends
+			from, to := one, nine
+			if from > to {
+				from, to = to, from
+			}
+			sum, empty := 0, 0
+			for i := from + 1; i < to; i++ {
+				if i == at || values[i] == 0 {
+					empty++
+				} else {
+					sum += values[i]
+				}
+			}
+
+			// The empty cells between take different digits, other than 1
+			// and 9 and those already in the line
+			inside := used | 1<<1 | 1<<9
+			between := sumCandidates(inside, sandwich.Sum-sum, empty)
+			reachable := sumReachable(inside, sandwich.Sum-sum, empty)
+			switch {
+			case at == one && reachable:
+				allowed |= 1 << 1
+			case at == nine && reachable:
+				allowed |= 1 << 9
+			case at > from && at < to:
+				allowed |= between
+			case reachable:
+				allowed |= AllDigits &^ (1<<1 | 1<<9)
+				if at < 0 {
+					return AllDigits // Off the line: any completion will do
+				}
+			}
+		}
+	}
+	return allowed
+}
+
+// fitsEnd checks if digit (1 or 9) can go at position i of the line: the
+// cell is empty or holds it, and it is not placed anywhere else
+func fitsEnd(values [9]int, i, digit int, used uint16) bool {
+	if values[i] == digit {
+		return true
+	}
+	return values[i] == 0 && used&(1<<digit) == 0
+}
@@ -0,0 +1,201 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"sudoku/utils"
+)
+
+// Kinds of cell pairs
+const (
+	UnitWhiteDot       Unit = "white dot"            // Kropki dot: consecutive digits
+	UnitBlackDot       Unit = "black dot"            // Kropki dot: one digit double the other
+	UnitNoDot          Unit = "dotless pair"         // Neighbours without a dot (negative constraint)
+	UnitNonConsecutive Unit = "non-consecutive pair" // Neighbours of the non-consecutive rule
+)
+
+// ErrInvalidDots is returned for Kropki dots that are not between two
+// neighbouring cells, or share their cells with another dot
+var ErrInvalidDots = errors.New("Error: Invalid dots")
+
+// Relation is a rule two cells must satisfy together, such as a Kropki dot
+type Relation struct {
+	Unit    Unit       // Kind of pair, used in conflict messages
+	allowed [10]uint16 // allowed[a] masks the digits B may hold when A holds a
+	reverse [10]uint16 // reverse[b] masks the digits A may hold when B holds b
+	word    string     // Joins the two digits in conflict messages
+}
+
+// NewRelation creates a relation from a test of the digits of a pair's
+// A and B cells
+func NewRelation(unit Unit, ok func(a, b int) bool) *Relation {
+	r := &Relation{Unit: unit, word: "next to"}
+	for a := 1; a <= 9; a++ {
+		for b := 1; b <= 9; b++ {
+			if ok(a, b) {
+				r.allowed[a] |= 1 << b
+				r.reverse[b] |= 1 << a
+			}
+		}
+	}
+	return r
+}
+
+// Allows checks if a and b may sit together
+func (r *Relation) Allows(a, b int) bool {
+	return r.allowed[a]&(1<<b) != 0
+}
+
+// The built-in relations
+var (
+	consecutive    = NewRelation(UnitWhiteDot, func(a, b int) bool { return a-b == 1 || b-a == 1 })
+	double         = NewRelation(UnitBlackDot, func(a, b int) bool { return a == 2*b || b == 2*a })
+	noDot          = NewRelation(UnitNoDot, func(a, b int) bool { return a-b != 1 && b-a != 1 && a != 2*b && b != 2*a })
+	nonConsecutive = NewRelation(UnitNonConsecutive, func(a, b int) bool { return a-b != 1 && b-a != 1 })
+)
+
+// Pair applies a relation to two cells
+type Pair struct {
+	Relation *Relation
+	Index    int // Number of the pair within its kind
+	A, B     Cell
+}
+
+// PairsOf returns the indexes (into Pairs) of the pairs holding (row, col)
+func (v *Variant) PairsOf(row, col int) []int {
+	if v == nil {
+		return nil
+	}
+	return v.pairsOf[row][col]
+}
+
+// pairsAllow returns the digits (row, col) may hold given the filled cells
+// it is paired with
+func (v *Variant) pairsAllow(board *utils.Board, row, col int) uint16 {
+	allowed := AllDigits
+	for _, index := range v.PairsOf(row, col) {
+		pair := v.Pairs[index]
+		if pair.A == (Cell{row, col}) {
+			if num := board[pair.B.Row][pair.B.Col]; num != 0 {
+				allowed &= pair.Relation.reverse[num]
+			}
+		} else if num := board[pair.A.Row][pair.A.Col]; num != 0 {
+			allowed &= pair.Relation.allowed[num]
+		}
+	}
+	return allowed
+}
+
+// pairConflicts reports every pair whose two filled cells break its relation
+func (v *Variant) pairConflicts(board *utils.Board) []Conflict {
+	var conflicts []Conflict
+	for _, pair := range v.Pairs {
+		a, b := board[pair.A.Row][pair.A.Col], board[pair.B.Row][pair.B.Col]
+		if a == 0 || b == 0 || pair.Relation.Allows(a, b) {
+			continue
+		}
+		conflicts = append(conflicts, Conflict{
+			Unit:   pair.Relation.Unit,
+			Index:  pair.Index,
+			First:  pair.A,
+			Second: pair.B,
+			Rule:   fmt.Sprintf("%d %s %d", a, pair.Relation.word, b),
+		})
+	}
+	return conflicts
+}
+
+// NonConsecutive returns the variant where orthogonal neighbours may not
+// hold consecutive digits
+func NonConsecutive() *Variant {
+	var pairs []Pair
+	for _, neighbours := range orthogonalPairs() {
+		pairs = append(pairs, Pair{Relation: nonConsecutive, Index: len(pairs), A: neighbours[0], B: neighbours[1]})
+	}
+	return NewPairVariant("non-consecutive", pairs)
+}
+
+// Dot is a Kropki dot between two orthogonal neighbours
+// A white dot joins consecutive digits, a black dot a digit and its double
+type Dot struct {
+	Black bool
+	A, B  Cell
+}
+
+// Kropki creates the variant for a set of Kropki dots
+// With negative set, neighbours without a dot may be neither consecutive
+// nor one double the other
+// Returns ErrInvalidDots if a dot is off the board, not between neighbours,
+// or on the same two cells as another dot
+func Kropki(dots []Dot, negative bool) (*Variant, error) {
+	dotted := map[[2]Cell]bool{}
+	var pairs []Pair
+	whites, blacks := 0, 0
+	for i, dot := range dots {
+		if !onBoard(dot.A) || !onBoard(dot.B) || !neighbours(dot.A, dot.B) {
+			return nil, fmt.Errorf("%w: dot %d is not between two neighbouring cells", ErrInvalidDots, i)
+		}
+		key := orderedPair(dot.A, dot.B)
+		if dotted[key] {
+			return nil, fmt.Errorf("%w: dot %d repeats the cells of an earlier dot", ErrInvalidDots, i)
+		}
+		dotted[key] = true
+
+		pair := Pair{Relation: consecutive, Index: whites, A: key[0], B: key[1]}
+		if dot.Black {
+			pair.Relation, pair.Index = double, blacks
+			blacks++
+		} else {
+			whites++
+		}
+		pairs = append(pairs, pair)
+	}
+
+	// Every other pair of neighbours has no dot
+	if negative {
+		index := 0
+		for _, cells := range orthogonalPairs() {
+			if !dotted[cells] {
+				pairs = append(pairs, Pair{Relation: noDot, Index: index, A: cells[0], B: cells[1]})
+				index++
+			}
+		}
+	}
+	return NewPairVariant("kropki", pairs), nil
+}
+
+// orthogonalPairs lists every pair of orthogonal neighbours, earlier cell
+// first, in reading order
+func orthogonalPairs() [][2]Cell {
+	var pairs [][2]Cell
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if col < 8 {
+				pairs = append(pairs, [2]Cell{{row, col}, {row, col + 1}})
+			}
+			if row < 8 {
+				pairs = append(pairs, [2]Cell{{row, col}, {row + 1, col}})
+			}
+		}
+	}
+	return pairs
+}
+
+// orderedPair puts two cells in reading order
+func orderedPair(a, b Cell) [2]Cell {
+	if b.Row*9+b.Col < a.Row*9+a.Col {
+		return [2]Cell{b, a}
+	}
+	return [2]Cell{a, b}
+}
+
+// onBoard checks if a cell lies on the 9x9 board
+func onBoard(cell Cell) bool {
+	return cell.Row >= 0 && cell.Row < 9 && cell.Col >= 0 && cell.Col < 9
+}
+
+// neighbours checks if two cells share a side
+func neighbours(a, b Cell) bool {
+	dr, dc := a.Row-b.Row, a.Col-b.Col
+	return dr*dr+dc*dc == 1
+}
@@ -0,0 +1,129 @@
+package validator
+
+import (
+	"math/bits"
+	"sudoku/utils"
+)
+
+// AllDigits is the candidate mask with every digit 1-9 set
+const AllDigits uint16 = 0x3FE
+
+// Tracker keeps a board together with bitmasks of the digits already used
+// in every row, column and box, so a placement can be checked in O(1)
+// Bit n of a mask is set when digit n is present
+type Tracker struct {
+	board   *utils.Board
+	rows    [9]uint16
+	cols    [9]uint16
+	boxes   [9]uint16
+	variant *Variant
+	regions []uint16     // One mask per variant region
+	sums    []int        // Total of the digits in each variant region
+	rules   []Constraint // Variant rules checked cell by cell, without masks
+}
+
+// NewTracker builds the masks for the digits already on the board
+// The board should be consistent (see ValidateBoard)
+func NewTracker(board *utils.Board) *Tracker {
+	return NewVariantTracker(board, nil)
+}
+
+// NewVariantTracker is NewTracker that also enforces the regions of a variant
+// The board should be consistent (see ValidateVariant)
+func NewVariantTracker(board *utils.Board, v *Variant) *Tracker {
+	t := &Tracker{board: board, variant: v, rules: v.searchRules()}
+	if v != nil {
+		t.regions = make([]uint16, len(v.Regions))
+		t.sums = make([]int, len(v.Regions))
+	}
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if num := board[row][col]; num != 0 {
+				t.mark(row, col, num)
+			}
+		}
+	}
+	return t
+}
+
+// Board returns the board the tracker is updating
+func (t *Tracker) Board() *utils.Board {
+	return t.board
+}
+
+// Variant returns the variant whose rules the tracker enforces (nil for classic)
+func (t *Tracker) Variant() *Variant {
+	return t.variant
+}
+
+// CanPlace checks if num fits at (row, col) without breaking a rule
+// Same result as IsValid, but without scanning the row, column and box
+func (t *Tracker) CanPlace(row, col, num int) bool {
+	return t.Candidates(row, col)&(1<<num) != 0
+}
+
+// Candidates returns the mask of digits not yet used by the cell's row,
+// column, box and variant regions (the cell's own digit counts as used)
+// Regions with a sum also drop digits that leave the sum out of reach, and
+// every other rule of the variant (pairs, arrows, sandwiches and caller
+// constraints) drops the digits it does not allow
+func (t *Tracker) Candidates(row, col int) uint16 {
+	used := t.rows[row] | t.cols[col] | t.boxes[t.variant.BoxIndex(row, col)]
+	allowed := AllDigits
+	for _, rule := range t.rules {
+		allowed &= rule.Candidates(t.board, row, col)
+	}
+	for _, index := range t.variant.RegionsOf(row, col) {
+		used |= t.regions[index]
+		if region := t.variant.Regions[index]; region.Sum > 0 {
+			left := len(region.Cells) - CountCandidates(t.regions[index])
+			allowed &= sumCandidates(t.regions[index], region.Sum-t.sums[index], left)
+		}
+	}
+	return allowed &^ used
+}
+
+// Place writes num at (row, col) and marks it as used
+func (t *Tracker) Place(row, col, num int) {
+	t.board[row][col] = num
+	t.mark(row, col, num)
+}
+
+// Remove clears (row, col) and frees its digit again
+func (t *Tracker) Remove(row, col int) {
+	num := t.board[row][col]
+	if num == 0 {
+		return
+	}
+	bit := uint16(1) << num
+	t.rows[row] &^= bit
+	t.cols[col] &^= bit
+	t.boxes[t.variant.BoxIndex(row, col)] &^= bit
+	for _, index := range t.variant.RegionsOf(row, col) {
+		t.regions[index] &^= bit
+		t.sums[index] -= num
+	}
+	t.board[row][col] = 0
+}
+
+// mark sets the bit for num in the cell's row, column, box and regions
+func (t *Tracker) mark(row, col, num int) {
+	bit := uint16(1) << num
+	t.rows[row] |= bit
+	t.cols[col] |= bit
+	t.boxes[t.variant.BoxIndex(row, col)] |= bit
+	for _, index := range t.variant.RegionsOf(row, col) {
+		t.regions[index] |= bit
+		t.sums[index] += num
+	}
+}
+
+// BoxIndex returns the number (0-8) of the 3x3 box containing (row, col)
+func BoxIndex(row, col int) int {
+	return (row/3)*3 + col/3
+}
+
+// CountCandidates returns how many digits are set in a candidate mask
+func CountCandidates(mask uint16) int {
+	return bits.OnesCount16(mask)
+}
@@ -1,14 +1,17 @@
 package validator
 
-import "sudoku/utils"
+import (
+	"fmt"
+	"sudoku/utils"
+)
 
 // IsValid checks if placing num at (row, col) is valid
-// Returns true if placement follows all Sudoku rules
+// Returns true if placement follows all Sudoku rules: the Rows, Columns and
+// Boxes constraints, and any added with RegisterConstraint
+// Variant.IsValid also checks a variant's own constraints
+// Scans the row, column and box each call; searches should use a Tracker
 func IsValid(board *utils.Board, row, col, num int) bool {
-	// Check all three rules
-	return isRowValid(board, row, num) &&
-		isColValid(board, col, num) &&
-		isBoxValid(board, row, col, num)
+	return (*Variant)(nil).IsValid(board, row, col, num)
 }
 
 // isRowValid checks if num already exists in the row
@@ -47,3 +50,151 @@ func isBoxValid(board *utils.Board, row, col, num int) bool {
 	}
 	return true
 }
+
+// Unit names the kind of group a conflict was found in
+type Unit string
+
+const (
+	UnitRow    Unit = "row"
+	UnitColumn Unit = "column"
+	UnitBox    Unit = "box"
+)
+
+// Cell is a (row, col) position on the board
+type Cell struct {
+	Row, Col int
+}
+
+// Conflict describes two givens with the same digit in one row, column or box
+// Variants also report givens breaking other rules, explained by Rule
+type Conflict struct {
+	Unit   Unit   // Kind of group the duplicate was found in
+	Index  int    // Which row, column or box (0-8 on a classic board)
+	Digit  int    // The repeated digit
+	First  Cell   // Earlier cell in reading order
+	Second Cell   // Later cell in reading order
+	Rule   string // Broken rule other than a repeat, e.g. "sum 17 exceeds 15"
+}
+
+// String formats the conflict as a one-line diagnostic
+func (c Conflict) String() string {
+	problem := fmt.Sprintf("duplicate %d", c.Digit)
+	if c.Rule != "" {
+		problem = c.Rule
+	}
+	return fmt.Sprintf("%s in %s %d at (%d, %d) and (%d, %d)",
+		problem, c.Unit, c.Index,
+		c.First.Row, c.First.Col, c.Second.Row, c.Second.Col)
+}
+
+// ValidateBoard checks the filled cells of the board against each other
+// Returns every conflicting pair of digits, or nil if the board is consistent
+func ValidateBoard(board *utils.Board) []Conflict {
+	return ValidateGrid(utils.GridFromBoard(board))
+}
+
+// ValidateGrid is ValidateBoard for a grid of any shape
+// Conflicts are reported for every row, then every column, then every box
+func ValidateGrid(g *utils.Grid) []Conflict {
+	var conflicts []Conflict
+	kinds := []Unit{UnitRow, UnitColumn, UnitBox}
+
+	// Check every row, column and box in turn
+	for i, cells := range ShapeUnits(g.Shape) {
+		kind, index := kinds[i/g.Size()], i%g.Size()
+		conflicts = append(conflicts, findDuplicates(g, kind, index, cells)...)
+	}
+
+	return conflicts
+}
+
+// findDuplicates compares every pair of filled cells within one unit
+func findDuplicates(g *utils.Grid, unit Unit, index int, cells []Cell) []Conflict {
+	var conflicts []Conflict
+	for i := 0; i < len(cells); i++ {
+		digit := g.Cells[cells[i].Row][cells[i].Col]
+		if digit == 0 {
+			continue // Empty cells never conflict
+		}
+		for j := i + 1; j < len(cells); j++ {
+			if g.Cells[cells[j].Row][cells[j].Col] == digit {
+				conflicts = append(conflicts, Conflict{
+					Unit:   unit,
+					Index:  index,
+					Digit:  digit,
+					First:  cells[i],
+					Second: cells[j],
+				})
+			}
+		}
+	}
+	return conflicts
+}
+
+// RowCells lists the cells of a row from left to right
+func RowCells(row int) []Cell {
+	cells := make([]Cell, 0, 9)
+	for col := 0; col < 9; col++ {
+		cells = append(cells, Cell{row, col})
+	}
+	return cells
+}
+
+// ColCells lists the cells of a column from top to bottom
+func ColCells(col int) []Cell {
+	cells := make([]Cell, 0, 9)
+	for row := 0; row < 9; row++ {
+		cells = append(cells, Cell{row, col})
+	}
+	return cells
+}
+
+// BoxCells lists the cells of a 3x3 box in reading order
+// Boxes are numbered 0-8 from top-left to bottom-right
+func BoxCells(box int) []Cell {
+	boxRow := (box / 3) * 3
+	boxCol := (box % 3) * 3
+
+	cells := make([]Cell, 0, 9)
+	for r := boxRow; r < boxRow+3; r++ {
+		for c := boxCol; c < boxCol+3; c++ {
+			cells = append(cells, Cell{r, c})
+		}
+	}
+	return cells
+}
+
+// AllUnits lists the cells of all 27 units: rows 0-8, columns 0-8, then boxes 0-8
+func AllUnits() [][]Cell {
+	units := make([][]Cell, 0, 27)
+	for i := 0; i < 9; i++ {
+		units = append(units, RowCells(i))
+	}
+	for i := 0; i < 9; i++ {
+		units = append(units, ColCells(i))
+	}
+	for i := 0; i < 9; i++ {
+		units = append(units, BoxCells(i))
+	}
+	return units
+}
+
+// ShapeUnits lists the units of a grid of any shape: rows, then columns,
+// then boxes, each numbered from 0 and listed in reading order
+func ShapeUnits(shape utils.Shape) [][]Cell {
+	size := shape.Size()
+	units := make([][]Cell, 3*size)
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			units[i] = append(units[i], Cell{i, j})
+			units[size+i] = append(units[size+i], Cell{j, i})
+		}
+	}
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			box := shape.Box(row, col)
+			units[2*size+box] = append(units[2*size+box], Cell{row, col})
+		}
+	}
+	return units
+}
@@ -0,0 +1,390 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sudoku/utils"
+)
+
+// Kinds of variant regions
+const (
+	UnitDiagonal Unit = "diagonal" // The two main diagonals of Sudoku-X
+	UnitWindow   Unit = "window"   // The four extra 3x3 windows of Windoku
+	UnitKnight   Unit = "knight move"
+	UnitKing     Unit = "king move"
+)
+
+// ErrInvalidRegions is returned for jigsaw region maps that are not nine
+// connected regions of nine cells
+var ErrInvalidRegions = errors.New("Error: Invalid region map")
+
+// Region is an extra group of cells that may not repeat a digit
+// A region of nine cells must hold every digit exactly once
+// A region with a Sum (a killer cage) must also add up to it
+type Region struct {
+	Unit  Unit // Kind of region, used in conflict messages
+	Index int  // Number of the region within its kind
+	Cells []Cell
+	Sum   int // Target total of the digits, 0 for none
+}
+
+// Variant adds rules to the classic row, column and box checks
+// Jigsaw variants also replace the 3x3 boxes with irregular regions
+// A nil *Variant is classic sudoku, and every method accepts it
+type Variant struct {
+	Name    string
+	Regions []Region
+	Pairs   []Pair // Cells whose digits must satisfy a relation
+
+	Arrows     []Arrow    // Circles holding the sum of their arrow
+	Sandwiches []Sandwich // Sums between the 1 and the 9 of a line
+
+	// Rules supplied by the caller (see NewConstraintVariant)
+	Constraints []Constraint
+
+	// regionsOf, pairsOf, arrowsOf and sandwichesOf list, for every cell,
+	// the indexes of the regions, pairs, arrows and sandwiches holding it
+	regionsOf    [9][9][]int
+	pairsOf      [9][9][]int
+	arrowsOf     [9][9][]int
+	sandwichesOf [9][9][]int
+
+	// boxOf maps every cell to its jigsaw region (nil for 3x3 boxes)
+	boxOf *[9][9]int
+}
+
+// NewVariant creates a variant from its extra regions
+func NewVariant(name string, regions []Region) *Variant {
+	v := &Variant{Name: name, Regions: regions}
+	v.index()
+	return v
+}
+
+// NewPairVariant creates a variant from pairs of related cells
+func NewPairVariant(name string, pairs []Pair) *Variant {
+	v := &Variant{Name: name, Pairs: pairs}
+	v.index()
+	return v
+}
+
+// index records which regions, pairs, arrows and sandwiches hold every cell
+func (v *Variant) index() {
+	for i, region := range v.Regions {
+		for _, cell := range region.Cells {
+			v.regionsOf[cell.Row][cell.Col] = append(v.regionsOf[cell.Row][cell.Col], i)
+		}
+	}
+	for i, pair := range v.Pairs {
+		v.pairsOf[pair.A.Row][pair.A.Col] = append(v.pairsOf[pair.A.Row][pair.A.Col], i)
+		v.pairsOf[pair.B.Row][pair.B.Col] = append(v.pairsOf[pair.B.Row][pair.B.Col], i)
+	}
+	for i, arrow := range v.Arrows {
+		for _, cell := range append([]Cell{arrow.Circle}, arrow.Cells...) {
+			v.arrowsOf[cell.Row][cell.Col] = append(v.arrowsOf[cell.Row][cell.Col], i)
+		}
+	}
+	for i, sandwich := range v.Sandwiches {
+		for _, cell := range sandwich.cells() {
+			v.sandwichesOf[cell.Row][cell.Col] = append(v.sandwichesOf[cell.Row][cell.Col], i)
+		}
+	}
+}
+
+// Combine merges variants into one that enforces all of their rules
+// Nil variants are skipped; names are joined with commas
+// Only one variant may replace the boxes; if several do, the first wins
+func Combine(variants ...*Variant) *Variant {
+	var names []string
+	combined := &Variant{}
+	for _, v := range variants {
+		if v == nil {
+			continue
+		}
+		names = append(names, v.Name)
+		combined.Regions = append(combined.Regions, v.Regions...)
+		combined.Pairs = append(combined.Pairs, v.Pairs...)
+		combined.Arrows = append(combined.Arrows, v.Arrows...)
+		combined.Sandwiches = append(combined.Sandwiches, v.Sandwiches...)
+		combined.Constraints = append(combined.Constraints, v.Constraints...)
+		if combined.boxOf == nil {
+			combined.boxOf = v.boxOf
+		}
+	}
+	combined.Name = strings.Join(names, ",")
+	combined.index()
+	return combined
+}
+
+// Jigsaw creates a variant whose boxes are the irregular regions of a map
+// boxes[row][col] numbers the region (0-8) of every cell
+// Returns ErrInvalidRegions unless there are nine regions of nine
+// orthogonally connected cells
+func Jigsaw(boxes [9][9]int) (*Variant, error) {
+	var cells [9][]Cell
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			box := boxes[row][col]
+			if box < 0 || box > 8 {
+				return nil, fmt.Errorf("%w: region %d at (%d, %d) is not 0-8", ErrInvalidRegions, box, row, col)
+			}
+			cells[box] = append(cells[box], Cell{row, col})
+		}
+	}
+
+	for box, region := range cells {
+		if len(region) != 9 {
+			return nil, fmt.Errorf("%w: region %d has %d cells, expected 9", ErrInvalidRegions, box, len(region))
+		}
+		if !connected(region) {
+			return nil, fmt.Errorf("%w: region %d is not connected", ErrInvalidRegions, box)
+		}
+	}
+
+	v := NewVariant("jigsaw", nil)
+	v.boxOf = &boxes
+	return v, nil
+}
+
+// connected checks if every cell of a region can be reached from the first
+// through orthogonal neighbours inside the region
+func connected(region []Cell) bool {
+	reached := []Cell{region[0]}
+	for i := 0; i < len(reached); i++ {
+		for _, cell := range region {
+			if neighbours(cell, reached[i]) && !containsCell(reached, cell) {
+				reached = append(reached, cell)
+			}
+		}
+	}
+	return len(reached) == len(region)
+}
+
+// containsCell checks if cell is in cells
+func containsCell(cells []Cell, cell Cell) bool {
+	for _, c := range cells {
+		if c == cell {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the name of the variant, "classic" for nil
+func (v *Variant) String() string {
+	if v == nil {
+		return "classic"
+	}
+	return v.Name
+}
+
+// RegionsOf returns the indexes (into Regions) of the regions holding (row, col)
+func (v *Variant) RegionsOf(row, col int) []int {
+	if v == nil {
+		return nil
+	}
+	return v.regionsOf[row][col]
+}
+
+// BoxIndex returns the box (0-8) holding (row, col): a jigsaw region, or
+// the 3x3 box for other variants
+func (v *Variant) BoxIndex(row, col int) int {
+	if v == nil || v.boxOf == nil {
+		return BoxIndex(row, col)
+	}
+	return v.boxOf[row][col]
+}
+
+// HasIrregularBoxes checks if the variant replaces the 3x3 boxes (jigsaw)
+func (v *Variant) HasIrregularBoxes() bool {
+	return v != nil && v.boxOf != nil
+}
+
+// ExactCover checks if every rule of the variant is a region, so the whole
+// puzzle is an exact cover problem (no sums, pairs, arrows, sandwiches,
+// caller constraints or registered ones)
+func (v *Variant) ExactCover() bool {
+	return !v.HasSums() && len(v.searchRules()) == 0
+}
+
+// BoxCells lists the cells of a box in reading order (see BoxIndex)
+func (v *Variant) BoxCells(box int) []Cell {
+	if v == nil || v.boxOf == nil {
+		return BoxCells(box)
+	}
+	var cells []Cell
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if v.boxOf[row][col] == box {
+				cells = append(cells, Cell{row, col})
+			}
+		}
+	}
+	return cells
+}
+
+// Units lists every group of nine cells that must hold each digit exactly
+// once: rows 0-8, columns 9-17, boxes 18-26, then full regions
+func (v *Variant) Units() [][]Cell {
+	units := AllUnits()
+	if v == nil {
+		return units
+	}
+	for box := 0; box < 9; box++ {
+		units[18+box] = v.BoxCells(box)
+	}
+	for _, region := range v.Regions {
+		if len(region.Cells) == 9 {
+			units = append(units, region.Cells)
+		}
+	}
+	return units
+}
+
+// IsValid checks if num can be placed at (row, col) under every rule of the
+// variant (see Rules), leaving every sum reachable
+func (v *Variant) IsValid(board *utils.Board, row, col, num int) bool {
+	for _, rule := range v.checks() {
+		if !rule.Check(board, row, col, num) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateVariant is ValidateBoard for a variant: the conflicts of every
+// rule in turn (see Rules), so rows, columns and boxes (jigsaw regions if
+// the variant has them) come first, then extra regions, region sums, pairs,
+// arrows, sandwiches, the caller's constraints and the registered ones
+func ValidateVariant(board *utils.Board, v *Variant) []Conflict {
+	if v == nil && len(registeredRules) == 0 {
+		return ValidateBoard(board)
+	}
+
+	var conflicts []Conflict
+	for _, rule := range v.Rules() {
+		conflicts = append(conflicts, rule.Conflicts(board)...)
+	}
+	return conflicts
+}
+
+// Diagonals returns the Sudoku-X variant: both main diagonals must also
+// hold every digit exactly once
+func Diagonals() *Variant {
+	var main, anti []Cell
+	for i := 0; i < 9; i++ {
+		main = append(main, Cell{i, i})
+		anti = append(anti, Cell{i, 8 - i})
+	}
+	return NewVariant("x", []Region{
+		{Unit: UnitDiagonal, Index: 0, Cells: main},
+		{Unit: UnitDiagonal, Index: 1, Cells: anti},
+	})
+}
+
+// Windows returns the Windoku (hyper sudoku) variant: four more 3x3 regions,
+// with top-left corners at (1, 1), (1, 5), (5, 1) and (5, 5), must also
+// hold every digit exactly once
+func Windows() *Variant {
+	var regions []Region
+	for _, top := range []int{1, 5} {
+		for _, left := range []int{1, 5} {
+			var cells []Cell
+			for row := top; row < top+3; row++ {
+				for col := left; col < left+3; col++ {
+					cells = append(cells, Cell{row, col})
+				}
+			}
+			regions = append(regions, Region{Unit: UnitWindow, Index: len(regions), Cells: cells})
+		}
+	}
+	return NewVariant("windoku", regions)
+}
+
+// AntiKnight returns the variant where cells a chess knight's move apart
+// may not hold the same digit
+func AntiKnight() *Variant {
+	moves := [][2]int{{-2, -1}, {-2, 1}, {-1, -2}, {-1, 2}, {1, -2}, {1, 2}, {2, -1}, {2, 1}}
+	return NewVariant("anti-knight", pairRegions(UnitKnight, moves))
+}
+
+// AntiKing returns the variant where cells a chess king's move apart may not
+// hold the same digit; only diagonal steps add to the classic rules
+func AntiKing() *Variant {
+	moves := [][2]int{{-1, -1}, {-1, 1}, {1, -1}, {1, 1}}
+	return NewVariant("anti-king", pairRegions(UnitKing, moves))
+}
+
+// pairRegions creates a two-cell region for every pair of cells one of the
+// moves apart, numbered in reading order of their first cell
+func pairRegions(unit Unit, moves [][2]int) []Region {
+	var regions []Region
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			for _, move := range moves {
+				r, c := row+move[0], col+move[1]
+				// Each pair once, from the cell earlier in reading order
+				if r < 0 || r > 8 || c < 0 || c > 8 || r*9+c < row*9+col {
+					continue
+				}
+				regions = append(regions, Region{Unit: unit, Index: len(regions), Cells: []Cell{{row, col}, {r, c}}})
+			}
+		}
+	}
+	return regions
+}
+
+// variants maps the names accepted by VariantByName to their constructors
+var variants = map[string]func() *Variant{
+	"x":               Diagonals,
+	"windoku":         Windows,
+	"anti-knight":     AntiKnight,
+	"anti-king":       AntiKing,
+	"non-consecutive": NonConsecutive,
+}
+
+// RegisterVariant makes a variant, such as one built with
+// NewConstraintVariant, available to VariantByName and ParseVariants
+// Registering the same name twice replaces the earlier constructor
+func RegisterVariant(name string, build func() *Variant) {
+	variants[name] = build
+}
+
+// VariantByName looks up a built-in variant by its command-line name
+// Returns an error listing the valid names if the name is unknown
+func VariantByName(name string) (*Variant, error) {
+	build, ok := variants[name]
+	if !ok {
+		return nil, fmt.Errorf("Error: Unknown variant %q (expected one of %v)", name, VariantNames())
+	}
+	return build(), nil
+}
+
+// VariantNames returns the names accepted by VariantByName, sorted
+func VariantNames() []string {
+	names := make([]string, 0, len(variants))
+	for name := range variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseVariants combines a comma separated list of variant names, such as
+// "x,windoku"; "" and "classic" mean no variant (nil)
+func ParseVariants(list string) (*Variant, error) {
+	if list == "" || list == "classic" {
+		return nil, nil
+	}
+
+	var parts []*Variant
+	for _, name := range strings.Split(list, ",") {
+		v, err := VariantByName(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, v)
+	}
+	return Combine(parts...), nil
+}